@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var abortCmd = &cobra.Command{
+	Use:   "abort",
+	Short: "Abort a restack that stopped on a conflict",
+	Long:  `Abort the gt operation (restack, sync, ...) that stopped on a rebase conflict. Every branch touched by the operation is reset to the commit it pointed at before the operation started, and the branch metadata is restored.`,
+	Args:  cobra.NoArgs,
+	RunE:  recordOperation(runAbort),
+}
+
+func runAbort(g git.Git, cmd *cobra.Command, args []string) error {
+	op, err := config.LoadOperation()
+	if err != nil {
+		return err
+	}
+	if op == nil {
+		return fmt.Errorf("no gt operation in progress")
+	}
+
+	if isRebaseInProgress(g) {
+		if err := abortRebase(g); err != nil {
+			return err
+		}
+	}
+
+	// Detach first so that the checked out branch can be moved as well
+	if err := detachHead(g); err != nil {
+		return err
+	}
+
+	for branchName, sha := range op.OriginalSHAs {
+		// Branches created by the operation did not exist before it
+		if sha == "" {
+			if err := deleteBranch(g, branchName); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := resetBranchTo(g, branchName, sha); err != nil {
+			return err
+		}
+	}
+
+	if op.OriginalConfig != nil {
+		if err := op.OriginalConfig.Save(); err != nil {
+			return fmt.Errorf("failed to restore config: %w", err)
+		}
+	}
+
+	if err := config.ClearOperation(); err != nil {
+		return err
+	}
+
+	returnTo := op.OriginalBranch
+	if op.AbortBranch != "" {
+		returnTo = op.AbortBranch
+	}
+	if err := checkoutBranch(g, returnTo); err != nil {
+		return err
+	}
+
+	fmt.Printf("Aborted gt %s and restored %d branch(es)\n", op.Command, len(op.OriginalSHAs))
+	return nil
+}
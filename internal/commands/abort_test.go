@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+// useScratchRepo runs the test in an empty directory with a .git dir, so that
+// config and operation state are saved there
+func useScratchRepo(t *testing.T) {
+	t.Helper()
+	tempDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tempDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+	t.Chdir(tempDir)
+}
+
+func TestAbortDeletesCreatedBranchesAndReturnsToStart(t *testing.T) {
+	useScratchRepo(t)
+
+	original := newStackConfig(map[string]string{"a": "main"})
+	op := &config.Operation{
+		Command:        "fold",
+		OriginalBranch: "a",
+		AbortBranch:    "b",
+		OriginalSHAs:   map[string]string{"a": "asha", "new": ""},
+		OriginalConfig: original,
+	}
+	if err := op.Save(); err != nil {
+		t.Fatalf("Failed to save operation: %v", err)
+	}
+
+	g := git.NewFake()
+	if err := runAbort(g, nil, nil); err != nil {
+		t.Fatalf("runAbort returned error: %v", err)
+	}
+
+	for _, call := range []string{"update-ref refs/heads/a asha", "branch -D new", "checkout b"} {
+		if !g.Called(call) {
+			t.Errorf("Expected '%s', got calls: %v", call, g.Calls)
+		}
+	}
+	if remaining, _ := config.LoadOperation(); remaining != nil {
+		t.Error("Expected the operation to be cleared")
+	}
+}
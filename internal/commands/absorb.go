@@ -0,0 +1,451 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var absorbCmd = &cobra.Command{
+	Use:   "absorb",
+	Short: "Amend staged hunks into the branches of the stack they belong to",
+	Long: `Amend each staged hunk into the branch of the current stack it belongs to.
+For every hunk, 'git blame' finds the commits that last touched the lines it changes (or the lines around an addition); the hunk is amended into the lowest branch that contains all of them, which is the highest of the branches those commits belong to.
+Hunks that only touch lines from trunk, add or delete whole files, or do not apply to their branch are left staged.
+Every branch stacked above an amended branch is restacked. If a rebase stops on a conflict, resolve it and run 'gt continue', or run 'gt abort' to roll back.`,
+	Args: cobra.NoArgs,
+	RunE: recordOperation(runAbsorb),
+}
+
+// absorbHunk is one hunk of the staged diff
+type absorbHunk struct {
+	// File is the path of the changed file and Header the diff lines before its first hunk
+	File   string
+	Header string
+	// Body is the hunk itself, starting with its @@ line
+	Body string
+	// Lines are the line numbers in HEAD's version of the file that decide
+	// where the hunk belongs: the lines it removes, or those around an addition
+	Lines []int
+}
+
+// patch returns the hunk as a patch that 'git apply' accepts on its own
+func (h absorbHunk) patch() string {
+	return h.Header + h.Body
+}
+
+func runAbsorb(g git.Git, cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+	if currentBranch == cfg.TrunkBranch {
+		return fmt.Errorf("Error: gt absorb cannot be run on %s", cfg.TrunkBranch)
+	}
+	if _, managed := cfg.ManagedBranches[currentBranch]; !managed {
+		return fmt.Errorf("branch '%s' is not tracked by gt", currentBranch)
+	}
+	if err := ensureNoOperationInProgress(); err != nil {
+		return err
+	}
+	if !hasStagedChanges(g) {
+		return fmt.Errorf("no staged changes to absorb; stage the fixes first with 'git add'")
+	}
+
+	// Untrimmed, so that a hunk ending in blank context lines keeps them
+	diff, err := g.RunRaw("diff", "--cached", "--no-color", "--no-ext-diff", "--no-renames")
+	if err != nil {
+		return fmt.Errorf("failed to read staged changes: %w", err)
+	}
+	hunks, skipped := parseStagedHunks(diff)
+	for _, file := range skipped {
+		fmt.Printf("Leaving '%s' staged: only changes to existing text files can be absorbed\n", file)
+	}
+
+	// Route every hunk to a branch of the stack below and including the current one
+	stack := append(ancestorsOf(cfg, currentBranch), currentBranch)
+	commitBranches, err := stackCommits(g, cfg, stack)
+	if err != nil {
+		return err
+	}
+	targets := map[string][]absorbHunk{}
+	for _, hunk := range hunks {
+		blame, err := blameLines(g, hunk.File, hunk.Lines)
+		if err != nil {
+			return err
+		}
+		target := chooseAbsorbTarget(hunk, blame, commitBranches, stack)
+		if target == "" {
+			fmt.Printf("Leaving a hunk of '%s' staged: its lines were not changed by a branch in this stack\n", hunk.File)
+			continue
+		}
+		targets[target] = append(targets[target], hunk)
+	}
+
+	// Build the amended commits first, so nothing changes until all of them exist
+	amended := map[string]string{}
+	lowest := ""
+	for _, branchName := range stack {
+		if len(targets[branchName]) == 0 {
+			continue
+		}
+		sha, applied, err := amendWithHunks(g, branchName, targets[branchName])
+		if err != nil {
+			return err
+		}
+		if applied < len(targets[branchName]) {
+			fmt.Printf("Leaving %d hunk(s) staged that do not apply to '%s'\n", len(targets[branchName])-applied, branchName)
+		}
+		if applied == 0 {
+			continue
+		}
+		amended[branchName] = sha
+		if lowest == "" {
+			lowest = branchName
+		}
+		fmt.Printf("Absorbing %d hunk(s) into '%s'\n", applied, branchName)
+	}
+	if lowest == "" {
+		fmt.Println("Nothing was absorbed")
+		return nil
+	}
+
+	before, err := takeSnapshot(g)
+	if err != nil {
+		return err
+	}
+
+	// Restacking rebases branches, which needs a clean working tree; the
+	// remaining changes are stashed and put back once it is done
+	descendants := descendantsOf(cfg, lowest)
+	stashed := len(descendants) > 0
+	if stashed {
+		if _, err := g.Run("stash", "push", "--quiet", "--message", "gt absorb"); err != nil {
+			return fmt.Errorf("failed to stash remaining changes: %w", err)
+		}
+	}
+
+	for branchName, sha := range amended {
+		if err := resetBranchTo(g, branchName, sha); err != nil {
+			return err
+		}
+	}
+	if _, ok := amended[currentBranch]; ok && stashed {
+		// The stash left the old tip checked out; match the amended one
+		if _, err := g.Run("reset", "--quiet", "--hard"); err != nil {
+			return fmt.Errorf("failed to update working tree: %w", err)
+		}
+	}
+
+	if err := startRestackFrom(g, cfg, before, "absorb", descendants, currentBranch); err != nil {
+		if stashed {
+			return fmt.Errorf("%w\nYour remaining changes are stashed; once the restack is complete, run 'git stash pop' to restore them", err)
+		}
+		return err
+	}
+
+	if stashed {
+		if err := restoreAbsorbStash(g); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("✔ gt absorb: amended %d branch(es)\n", len(amended))
+	return nil
+}
+
+// parseStagedHunks splits a staged diff into hunks. Files that are added,
+// deleted, binary or have an unusual path are returned separately, as they
+// cannot be matched to earlier commits line by line.
+func parseStagedHunks(diff string) ([]absorbHunk, []string) {
+	hunks := []absorbHunk{}
+	skipped := []string{}
+
+	for _, fileDiff := range splitFileDiffs(diff) {
+		headerEnd := strings.Index(fileDiff, "\n@@ ")
+		if headerEnd < 0 {
+			skipped = append(skipped, diffFileName(fileDiff))
+			continue
+		}
+		header := fileDiff[:headerEnd+1]
+		file := diffFileName(header)
+		if strings.Contains(header, "\nnew file mode") || strings.Contains(header, "\ndeleted file mode") || !strings.Contains(header, "\n--- a/") {
+			skipped = append(skipped, file)
+			continue
+		}
+
+		for _, body := range strings.SplitAfter(fileDiff[headerEnd+1:], "\n@@ ") {
+			body = strings.TrimSuffix(body, "@@ ")
+			if !strings.HasPrefix(body, "@@ ") {
+				body = "@@ " + body
+			}
+			lines, ok := hunkLines(body)
+			if !ok {
+				skipped = append(skipped, file)
+				continue
+			}
+			hunks = append(hunks, absorbHunk{File: file, Header: header, Body: strings.TrimSuffix(body, "\n") + "\n", Lines: lines})
+		}
+	}
+	return hunks, skipped
+}
+
+// splitFileDiffs splits a diff into the parts for each file
+func splitFileDiffs(diff string) []string {
+	files := []string{}
+	for _, part := range strings.Split("\n"+diff, "\ndiff --git ") {
+		if strings.TrimSpace(part) != "" {
+			files = append(files, "diff --git "+strings.TrimSuffix(part, "\n")+"\n")
+		}
+	}
+	return files
+}
+
+// diffFileName returns the path of the file a diff header is about
+func diffFileName(header string) string {
+	for _, line := range strings.Split(header, "\n") {
+		if name, ok := strings.CutPrefix(line, "--- a/"); ok {
+			return name
+		}
+		if name, ok := strings.CutPrefix(line, "+++ b/"); ok {
+			return name
+		}
+	}
+	first, _, _ := strings.Cut(header, "\n")
+	return strings.TrimPrefix(first, "diff --git ")
+}
+
+// hunkLines returns the lines of the old file that decide where a hunk
+// belongs: every removed line, and for an addition the lines on either side
+func hunkLines(body string) ([]int, bool) {
+	header, rest, _ := strings.Cut(body, "\n")
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return nil, false
+	}
+	start, count, ok := parseHunkRange(fields[1][1:])
+	if !ok {
+		return nil, false
+	}
+	last := start + count - 1
+
+	lines := []int{}
+	seen := map[int]bool{}
+	add := func(line int) {
+		if line >= 1 && line >= start && line <= last && !seen[line] {
+			seen[line] = true
+			lines = append(lines, line)
+		}
+	}
+
+	old := start
+	previous := byte(' ')
+	for _, line := range strings.Split(rest, "\n") {
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case ' ':
+			old++
+		case '-':
+			add(old)
+			old++
+		case '+':
+			if previous != '+' && previous != '-' {
+				add(old - 1)
+				add(old)
+			}
+		default:
+			// "\ No newline at end of file"
+			continue
+		}
+		previous = line[0]
+	}
+	return lines, len(lines) > 0
+}
+
+// parseHunkRange parses the "start,count" of a hunk header; count defaults to 1
+func parseHunkRange(spec string) (int, int, bool) {
+	startText, countText, hasCount := strings.Cut(spec, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return 0, 0, false
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, count, true
+}
+
+// stackCommits maps each of the stack's own commits to the branch it belongs to
+func stackCommits(g git.Git, cfg *config.Config, stack []string) (map[string]string, error) {
+	commitBranches := map[string]string{}
+	for _, branchName := range stack {
+		base, err := branchBase(g, cfg, branchName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find base of '%s': %w", branchName, err)
+		}
+		output, err := g.Run("rev-list", base+".."+branchName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits of '%s': %w", branchName, err)
+		}
+		for _, sha := range strings.Fields(output) {
+			commitBranches[sha] = branchName
+		}
+	}
+	return commitBranches, nil
+}
+
+// blameLines returns the commit that last changed each of the given lines of
+// a file in HEAD
+func blameLines(g git.Git, file string, lines []int) (map[int]string, error) {
+	first, last := lines[0], lines[0]
+	for _, line := range lines {
+		first, last = min(first, line), max(last, line)
+	}
+	output, err := g.Run("blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", first, last), "HEAD", "--", file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame '%s': %w", file, err)
+	}
+	return parseBlamePorcelain(output), nil
+}
+
+// parseBlamePorcelain maps line numbers to commits from 'git blame --porcelain'
+// output, whose line headers read "<sha> <original line> <final line> [<count>]"
+func parseBlamePorcelain(output string) map[int]string {
+	blame := map[int]string{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || strings.HasPrefix(line, "\t") || !isCommitSHA(fields[0]) {
+			continue
+		}
+		if final, err := strconv.Atoi(fields[2]); err == nil {
+			blame[final] = fields[0]
+		}
+	}
+	return blame
+}
+
+// isCommitSHA reports whether s is a full SHA-1 or SHA-256 object name
+func isCommitSHA(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// chooseAbsorbTarget returns the branch a hunk should be amended into: the
+// lowest branch of the stack that has every line the hunk depends on, which is
+// the highest branch whose commits last touched one of them. Returns "" if
+// none of the lines were touched by the stack.
+func chooseAbsorbTarget(hunk absorbHunk, blame map[int]string, commitBranches map[string]string, stack []string) string {
+	target := -1
+	for _, line := range hunk.Lines {
+		branchName, ok := commitBranches[blame[line]]
+		if !ok {
+			continue
+		}
+		for i, name := range stack {
+			if name == branchName && i > target {
+				target = i
+			}
+		}
+	}
+	if target < 0 {
+		return ""
+	}
+	return stack[target]
+}
+
+// amendWithHunks creates a commit that replaces the tip of a branch with the
+// given hunks applied to it, keeping its message and author. The commit is
+// built in a temporary index, so neither the branch nor the working tree
+// change. Returns the new commit and how many hunks applied; hunks that do
+// not apply are skipped.
+func amendWithHunks(g git.Git, branchName string, hunks []absorbHunk) (string, int, error) {
+	index, err := os.CreateTemp("", "gt-absorb-index-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	indexPath := index.Name()
+	index.Close()
+	os.Remove(indexPath)
+	defer os.Remove(indexPath)
+
+	applied := 0
+	indexed := g.WithEnv("GIT_INDEX_FILE=" + indexPath)
+	if _, err := indexed.Run("read-tree", branchName); err != nil {
+		return "", 0, fmt.Errorf("failed to read tree of '%s': %w", branchName, err)
+	}
+	for _, hunk := range hunks {
+		if _, err := indexed.RunWithInput(hunk.patch(), "apply", "--cached", "-"); err == nil {
+			applied++
+		}
+	}
+	if applied == 0 {
+		return "", 0, nil
+	}
+	tree, err := indexed.Run("write-tree")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to write tree: %w", err)
+	}
+
+	// Reuse the tip's parents, message and author, like 'git commit --amend'
+	parents, err := g.Run("rev-list", "--parents", "-n", "1", branchName)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read parents of '%s': %w", branchName, err)
+	}
+	message, err := g.Run("log", "-1", "--format=%B", branchName)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read commit message: %w", err)
+	}
+	env, err := authorEnv(g, branchName)
+	if err != nil {
+		return "", 0, err
+	}
+
+	commitArgs := []string{"commit-tree", tree}
+	for _, parent := range strings.Fields(parents)[1:] {
+		commitArgs = append(commitArgs, "-p", parent)
+	}
+	commitArgs = append(commitArgs, "-m", message)
+
+	sha, err := g.WithEnv(env...).Run(commitArgs...)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to amend '%s': %w", branchName, err)
+	}
+	return sha, applied, nil
+}
+
+// restoreAbsorbStash puts back the changes stashed before restacking: the
+// working tree as it was, and the index as it was, so that only the hunks
+// that were not absorbed remain staged
+func restoreAbsorbStash(g git.Git) error {
+	if _, err := g.Run("read-tree", "--reset", "-u", "stash@{0}^{tree}"); err != nil {
+		return fmt.Errorf("failed to restore your changes (they are still stashed; run 'git stash pop'): %w", err)
+	}
+	if _, err := g.Run("read-tree", "stash@{0}^2^{tree}"); err != nil {
+		return fmt.Errorf("failed to restore staged changes (they are still stashed): %w", err)
+	}
+	if _, err := g.Run("stash", "drop", "--quiet"); err != nil {
+		return fmt.Errorf("failed to drop stash: %w", err)
+	}
+	return nil
+}
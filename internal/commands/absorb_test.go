@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+const stagedDiff = `diff --git a/f b/f
+index 1111111..2222222 100644
+--- a/f
++++ b/f
+@@ -1,3 +1,3 @@
+ a1
+-a2
++A2
+ a3
+@@ -8,2 +8,3 @@ a7
+ a8
+ a9
++a10
+diff --git a/n b/n
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/n
+@@ -0,0 +1 @@
++new`
+
+func TestParseStagedHunks(t *testing.T) {
+	hunks, skipped := parseStagedHunks(stagedDiff)
+
+	if !reflect.DeepEqual(skipped, []string{"n"}) {
+		t.Errorf("Expected the new file to be skipped, got %v", skipped)
+	}
+	if len(hunks) != 2 {
+		t.Fatalf("Expected 2 hunks, got %d: %+v", len(hunks), hunks)
+	}
+
+	if hunks[0].File != "f" || !reflect.DeepEqual(hunks[0].Lines, []int{2}) {
+		t.Errorf("Expected the first hunk to depend on line 2 of 'f', got %+v", hunks[0])
+	}
+	if !reflect.DeepEqual(hunks[1].Lines, []int{9}) {
+		t.Errorf("Expected the addition to depend on the line before it, got %v", hunks[1].Lines)
+	}
+
+	expectedPatch := "diff --git a/f b/f\nindex 1111111..2222222 100644\n--- a/f\n+++ b/f\n@@ -8,2 +8,3 @@ a7\n a8\n a9\n+a10\n"
+	if hunks[1].patch() != expectedPatch {
+		t.Errorf("Expected patch:\n%s\ngot:\n%s", expectedPatch, hunks[1].patch())
+	}
+}
+
+func TestParseStagedHunksKeepsTrailingBlankContext(t *testing.T) {
+	// The last line of the diff is a blank context line: a single space
+	diff := "diff --git a/f b/f\nindex 1111111..2222222 100644\n--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n a1\n-a2\n+A2\n \n"
+
+	hunks, _ := parseStagedHunks(diff)
+	if len(hunks) != 1 {
+		t.Fatalf("Expected 1 hunk, got %d: %+v", len(hunks), hunks)
+	}
+	if expected := "@@ -1,3 +1,3 @@\n a1\n-a2\n+A2\n \n"; hunks[0].Body != expected {
+		t.Errorf("Expected the blank context line to be kept, got %q", hunks[0].Body)
+	}
+}
+
+func TestStagedHunkEndingInBlankContextApplies(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	g := git.NewExec(dir)
+	g.Env = []string{"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t"}
+	writeFile := func(content string) {
+		if err := os.WriteFile(filepath.Join(dir, "f"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	run := func(args ...string) {
+		if _, err := g.Run(args...); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+
+	run("init", "-q")
+	writeFile("a1\na2\na3\n\n")
+	run("add", "f")
+	run("commit", "-q", "-m", "Add f")
+	writeFile("a1\na2\nA3\n\n")
+	run("add", "f")
+
+	diff, err := g.RunRaw("diff", "--cached", "--no-color", "--no-ext-diff", "--no-renames")
+	if err != nil {
+		t.Fatalf("Failed to read staged diff: %v", err)
+	}
+	hunks, _ := parseStagedHunks(diff)
+	if len(hunks) != 1 {
+		t.Fatalf("Expected 1 hunk, got %d: %+v", len(hunks), hunks)
+	}
+
+	run("reset", "-q")
+	if _, err := g.RunWithInput(hunks[0].patch(), "apply", "--cached", "-"); err != nil {
+		t.Errorf("Expected the hunk to apply, got %v", err)
+	}
+}
+
+func TestHunkLinesForInsertion(t *testing.T) {
+	lines, ok := hunkLines("@@ -4,4 +4,5 @@\n a4\n a5\n+new\n a6\n a7\n")
+	if !ok {
+		t.Fatal("Expected the hunk to parse")
+	}
+	if !reflect.DeepEqual(lines, []int{5, 6}) {
+		t.Errorf("Expected the lines around the insertion, got %v", lines)
+	}
+}
+
+func TestParseBlamePorcelain(t *testing.T) {
+	sha1 := "1111111111111111111111111111111111111111"
+	sha2 := "2222222222222222222222222222222222222222"
+	output := sha1 + " 2 2 1\nauthor t\nfilename f\n\ta2\n" +
+		sha2 + " 3 3 2\nauthor t\nprevious " + sha1 + " f\nfilename f\n\ta3\n" +
+		sha2 + " 4 4\n\ta4\n"
+
+	blame := parseBlamePorcelain(output)
+	expected := map[int]string{2: sha1, 3: sha2, 4: sha2}
+	if !reflect.DeepEqual(blame, expected) {
+		t.Errorf("Expected %v, got %v", expected, blame)
+	}
+}
+
+func TestChooseAbsorbTarget(t *testing.T) {
+	stack := []string{"a", "b", "c"}
+	commitBranches := map[string]string{"ca": "a", "cb": "b"}
+	hunk := absorbHunk{Lines: []int{1, 2, 3}}
+
+	target := chooseAbsorbTarget(hunk, map[int]string{1: "ca", 2: "trunk", 3: "ca"}, commitBranches, stack)
+	if target != "a" {
+		t.Errorf("Expected 'a', got '%s'", target)
+	}
+
+	target = chooseAbsorbTarget(hunk, map[int]string{1: "cb", 2: "ca", 3: "trunk"}, commitBranches, stack)
+	if target != "b" {
+		t.Errorf("Expected the lowest branch with every line, 'b', got '%s'", target)
+	}
+
+	target = chooseAbsorbTarget(hunk, map[int]string{1: "trunk", 2: "trunk", 3: "trunk"}, commitBranches, stack)
+	if target != "" {
+		t.Errorf("Expected no target for lines from trunk, got '%s'", target)
+	}
+}
+
+func TestAbsorbAmendsLowerBranchAndRestacks(t *testing.T) {
+	useScratchRepo(t)
+
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+	})
+	setParentSHA(cfg, "a", "maintip")
+	setParentSHA(cfg, "b", "atip")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	shaA := strings.Repeat("a", 40)
+	shaB := strings.Repeat("b", 40)
+	shaMain := strings.Repeat("c", 40)
+
+	// The first hunk of 'f' changes a line from a's commit; the second one
+	// only touches a line from trunk
+	g := git.NewFake()
+	g.On("rev-parse --abbrev-ref HEAD", "b")
+	g.On("for-each-ref --format=%(refname:short) %(objectname) refs/heads", "a atip\nb btip\nmain maintip")
+	g.On("rev-parse --verify a^{commit}", "newa")
+	g.Fail("diff --cached --quiet")
+	g.On("diff --cached --no-color --no-ext-diff --no-renames", stagedDiff)
+	g.On("rev-list maintip..a", shaA)
+	g.On("rev-list atip..b", shaB)
+	g.On("blame --porcelain -L 2,2 HEAD -- f", shaA+" 2 2 1\n\ta2")
+	g.On("blame --porcelain -L 9,9 HEAD -- f", shaMain+" 9 9 1\n\ta9")
+	g.On("write-tree", "newtree")
+	g.On("rev-list --parents -n 1 a", "atip maintip")
+	g.On("log -1 --format=%B a", "Add a")
+	g.On("log -1 --format=%an%x00%ae%x00%ad --date=raw a", "Ada\x00ada@example.com\x001700000000 +0000")
+	g.On("commit-tree newtree -p maintip -m Add a", "newa")
+
+	if err := runAbsorb(g, nil, nil); err != nil {
+		t.Fatalf("runAbsorb returned error: %v", err)
+	}
+
+	for i, call := range g.Calls {
+		switch strings.Join(call, " ") {
+		case "apply --cached -":
+			if !strings.Contains(g.Inputs[i], "-a2") {
+				t.Errorf("Expected only the hunk changing a2 to be applied, got:\n%s", g.Inputs[i])
+			}
+			if env := g.Envs[i]; len(env) != 1 || !strings.HasPrefix(env[0], "GIT_INDEX_FILE=") {
+				t.Errorf("Expected the hunk to be applied to a temporary index, got env %v", env)
+			}
+		case "commit-tree newtree -p maintip -m Add a":
+			expected := []string{"GIT_AUTHOR_NAME=Ada", "GIT_AUTHOR_EMAIL=ada@example.com", "GIT_AUTHOR_DATE=1700000000 +0000"}
+			if !reflect.DeepEqual(g.Envs[i], expected) {
+				t.Errorf("Expected the amended commit to keep its author, got env %v", g.Envs[i])
+			}
+		}
+	}
+	if !g.Called("update-ref refs/heads/a newa") {
+		t.Errorf("Expected 'a' to be amended, got calls: %v", g.Calls)
+	}
+	if g.Called("read-tree b") {
+		t.Error("Expected nothing to be absorbed into 'b'")
+	}
+	if !g.Called("rebase --onto newa atip b") {
+		t.Errorf("Expected 'b' to be restacked onto the amended 'a', got calls: %v", g.Calls)
+	}
+
+	// The hunk from trunk is still staged once the stash is restored
+	if !g.Called("stash push --quiet --message gt absorb") || !g.Called("read-tree stash@{0}^2^{tree}") {
+		t.Errorf("Expected the remaining staged changes to be stashed and restored, got calls: %v", g.Calls)
+	}
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if b := saved.ManagedBranches["b"]; b.ParentSHA != "newa" {
+		t.Errorf("Expected 'b' based on the amended 'a', got %+v", b)
+	}
+}
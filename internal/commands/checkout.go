@@ -1,22 +1,195 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
 )
 
 var checkoutCmd = &cobra.Command{
 	Use:     "checkout [branch]",
 	Aliases: []string{"co"},
 	Short:   "Checkout a branch",
-	Long:    `Checkout to a branch. If no branch is supplied, list available branches with trunk branch at the bottom and most recently used above that, which you can navigate using up/down arrows to select from the list.`,
+	Long:    `Checkout to a branch. If no branch is supplied, list available branches with trunk branch at the bottom and the most recently used stacks above that, which you can navigate using up/down arrows to select from the list. Type to fuzzy filter the list; managed branches are indented by their depth in the stack. When not attached to a terminal, a numbered list is printed instead.`,
 	Args:    cobra.MaximumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("checkout command - not yet implemented")
-		if len(args) > 0 {
-			fmt.Printf("Branch: %s\n", args[0])
+	RunE:    withGit(runCheckout),
+}
+
+func runCheckout(g git.Git, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		if err := checkoutBranch(g, args[0]); err != nil {
+			return err
 		}
+		fmt.Printf("Checked out '%s'\n", args[0])
 		return nil
-	},
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+
+	branches, err := listBranchesByRecency(g, cfg.TrunkBranch)
+	if err != nil {
+		return err
+	}
+	items := checkoutItems(cfg, branches, currentBranch)
+	if len(items) == 0 {
+		return fmt.Errorf("no branches to check out")
+	}
+
+	selected := ""
+	err = errNoRawTerminal
+	if isInteractiveTerminal() {
+		// Start on the most recently used branch, or trunk if there is none
+		start := len(items) - 1
+		for i, item := range items {
+			if len(branches) > 0 && item.Name == branches[0] {
+				start = i
+			}
+		}
+		selected, err = runPicker("Select a branch to check out:", newPicker(items, start))
+	}
+	// Fall back to a numbered list when there is no terminal to draw the picker on
+	if errors.Is(err, errNoRawTerminal) {
+		labels := make([]string, len(items))
+		for i, item := range items {
+			labels[i] = item.Label
+		}
+		var index int
+		index, err = promptSelectIndex("Select a branch to check out:", labels)
+		if err == nil {
+			selected = items[index].Name
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if selected == "" {
+		return nil
+	}
+
+	if err := checkoutBranch(g, selected); err != nil {
+		return err
+	}
+	fmt.Printf("Checked out '%s'\n", selected)
+	return nil
+}
+
+// listBranchesByRecency returns local branches other than trunk, most recently
+// checked out first according to the reflog, followed by branches that were
+// never checked out ordered by their latest commit
+func listBranchesByRecency(g git.Git, trunkBranch string) ([]string, error) {
+	output, err := g.Run("for-each-ref", "--sort=-committerdate", "--format=%(refname:short)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	local := make(map[string]bool)
+	byCommitDate := []string{}
+	for _, name := range strings.Split(output, "\n") {
+		if name != "" {
+			local[name] = true
+			byCommitDate = append(byCommitDate, name)
+		}
+	}
+
+	// The reflog may be missing (e.g. core.logAllRefUpdates=false), which just means no recency data
+	reflog, _ := g.Run("reflog", "show", "-n", "1000", "--format=%gs", "HEAD")
+
+	ordered := []string{}
+	seen := map[string]bool{trunkBranch: true}
+	for _, name := range checkoutTargets(reflog) {
+		if local[name] && !seen[name] {
+			seen[name] = true
+			ordered = append(ordered, name)
+		}
+	}
+	for _, name := range byCommitDate {
+		if !seen[name] {
+			seen[name] = true
+			ordered = append(ordered, name)
+		}
+	}
+	return ordered, nil
+}
+
+// checkoutTargets extracts the branches moved to from reflog subjects, newest first
+func checkoutTargets(reflog string) []string {
+	targets := []string{}
+	for _, line := range strings.Split(reflog, "\n") {
+		if !strings.HasPrefix(line, "checkout: moving from ") {
+			continue
+		}
+		if i := strings.LastIndex(line, " to "); i >= 0 {
+			targets = append(targets, line[i+len(" to "):])
+		}
+	}
+	return targets
+}
+
+// checkoutItems builds the picker entries. Branches are grouped by stack, so
+// that their indentation by stack depth reads as a tree: stacks are ordered by
+// their most recently used branch, nearest trunk at the bottom, and each stack
+// is drawn bottom-up with its children above their parents.
+func checkoutItems(cfg *config.Config, branches []string, currentBranch string) []pickerItem {
+	local := make(map[string]bool)
+	for _, name := range branches {
+		local[name] = true
+	}
+
+	grouped := []string{}
+	placed := make(map[string]bool)
+	for _, name := range branches {
+		if placed[name] {
+			continue
+		}
+		root := name
+		if ancestors := ancestorsOf(cfg, name); len(ancestors) > 0 {
+			root = ancestors[0]
+		}
+		for _, member := range append([]string{root}, descendantsOf(cfg, root)...) {
+			if local[member] && !placed[member] {
+				placed[member] = true
+				grouped = append(grouped, member)
+			}
+		}
+	}
+
+	items := []pickerItem{}
+	for i := len(grouped) - 1; i >= 0; i-- {
+		items = append(items, checkoutItem(cfg, grouped[i], currentBranch))
+	}
+	return append(items, checkoutItem(cfg, cfg.TrunkBranch, currentBranch))
+}
+
+func checkoutItem(cfg *config.Config, name, currentBranch string) pickerItem {
+	label := strings.Repeat("  ", stackDepth(cfg, name)) + name
+	if name == currentBranch {
+		label += " (current)"
+	}
+	return pickerItem{Name: name, Label: label}
+}
+
+// stackDepth returns how many managed ancestors a branch has, counting itself
+// (trunk and unmanaged branches have depth 0)
+func stackDepth(cfg *config.Config, name string) int {
+	depth := 0
+	for range cfg.ManagedBranches {
+		branch, managed := cfg.ManagedBranches[name]
+		if !managed {
+			break
+		}
+		depth++
+		name = branch.Parent
+	}
+	return depth
 }
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckoutTargets(t *testing.T) {
+	reflog := `checkout: moving from feature-b to main
+commit: Add feature
+checkout: moving from main to feature-b
+rebase (finish): returning to refs/heads/feature-b
+checkout: moving from feature-a to main`
+
+	expected := []string{"main", "feature-b", "main"}
+	if got := checkoutTargets(reflog); !reflect.DeepEqual(got, expected) {
+		t.Errorf("checkoutTargets() = %v; want %v", got, expected)
+	}
+}
+
+func TestCheckoutItems(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"x": "main",
+		"y": "x",
+	})
+
+	// Most recent first, as returned by listBranchesByRecency: the two stacks
+	// are interleaved, but each is kept together so its indentation reads as a tree
+	items := checkoutItems(cfg, []string{"b", "y", "unmanaged", "a", "x"}, "b")
+
+	expected := []pickerItem{
+		{Name: "unmanaged", Label: "unmanaged"},
+		{Name: "y", Label: "    y"},
+		{Name: "x", Label: "  x"},
+		{Name: "b", Label: "    b (current)"},
+		{Name: "a", Label: "  a"},
+		{Name: "main", Label: "main"},
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("checkoutItems() = %+v; want %+v", items, expected)
+	}
+}
@@ -6,7 +6,7 @@ import (
 
 func TestRootCommandHasSubcommands(t *testing.T) {
 	// Verify all expected commands are registered
-	expectedCommands := []string{"create", "pop", "modify", "checkout", "sync", "restack", "submit"}
+	expectedCommands := []string{"create", "pop", "modify", "checkout", "sync", "restack", "submit", "continue", "abort", "undo", "oplog", "log", "up", "down", "top", "bottom", "storage", "track", "untrack", "move", "fold", "split", "squash", "reorder", "absorb", "rename"}
 	
 	for _, cmdName := range expectedCommands {
 		found := false
@@ -91,3 +91,34 @@ func TestSubmitCommandExists(t *testing.T) {
 		t.Errorf("submit command Use string is incorrect: %s", submitCmd.Use)
 	}
 }
+
+func TestCreateCommandHasTrackFlag(t *testing.T) {
+	if createCmd.Flags().Lookup("track") == nil {
+		t.Error("Expected 'track' flag to exist for create command")
+	}
+}
+
+func TestCreateCommandHasInsertFlag(t *testing.T) {
+	if createCmd.Flags().Lookup("insert") == nil {
+		t.Error("Expected 'insert' flag to exist for create command")
+	}
+}
+
+func TestModifyCommandHasNoRestackFlag(t *testing.T) {
+	if modifyCmd.Flags().Lookup("no-restack") == nil {
+		t.Error("Expected 'no-restack' flag to exist for modify command")
+	}
+}
+
+func TestModifyCommandHasCommitAndPatchFlags(t *testing.T) {
+	for name, shorthand := range map[string]string{"commit": "c", "message": "m", "patch": "p"} {
+		flag := modifyCmd.Flags().Lookup(name)
+		if flag == nil {
+			t.Errorf("Expected '%s' flag to exist for modify command", name)
+			continue
+		}
+		if flag.Shorthand != shorthand {
+			t.Errorf("Expected '%s' flag shorthand to be '%s', got '%s'", name, shorthand, flag.Shorthand)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var continueCmd = &cobra.Command{
+	Use:   "continue",
+	Short: "Continue a restack that stopped on a conflict",
+	Long:  `Continue the gt operation (restack, sync, ...) that stopped on a rebase conflict. Resolve the conflicts and stage them with 'git add' first; the rebase is then continued and the remaining branches of the stack are restacked.`,
+	Args:  cobra.NoArgs,
+	RunE:  recordOperation(runContinue),
+}
+
+func runContinue(g git.Git, cmd *cobra.Command, args []string) error {
+	op, err := config.LoadOperation()
+	if err != nil {
+		return err
+	}
+	if op == nil {
+		return fmt.Errorf("no gt operation in progress")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if isRebaseInProgress(g) {
+		if err := continueRebase(g); err != nil {
+			if isRebaseInProgress(g) {
+				return fmt.Errorf("%w\nResolve the remaining conflicts, stage them with 'git add', then run 'gt continue' again", err)
+			}
+			return err
+		}
+	}
+
+	// The conflicted branch is done only if it now sits on the new parent tip;
+	// otherwise (e.g. the rebase was aborted by hand) restack it again
+	if op.Current != "" {
+		if isAncestor(g, op.CurrentOnto, op.Current) {
+			branch := cfg.ManagedBranches[op.Current]
+			branch.ParentSHA = op.CurrentOnto
+			cfg.ManagedBranches[op.Current] = branch
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+		} else {
+			op.Remaining = append([]string{op.Current}, op.Remaining...)
+		}
+		op.Current = ""
+		op.CurrentOnto = ""
+	}
+
+	if err := resumeRestack(g, cfg, op); err != nil {
+		return err
+	}
+
+	fmt.Printf("gt %s complete!\n", op.Command)
+	return nil
+}
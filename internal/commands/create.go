@@ -5,90 +5,165 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
 )
 
 var (
 	createAll     bool
 	createMessage string
+	createTrack   bool
+	createInsert  bool
 )
 
 var createCmd = &cobra.Command{
 	Use:   "create [commit-message]",
 	Short: "Create a new branch and commit",
 	Long:  `Create a new branch and commit. The commit message is used to generate the branch name.
-Provide the commit message as a positional argument or via -m flag (positional takes precedence if both provided).`,
+Provide the commit message as a positional argument or via -m flag (positional takes precedence if both provided).
+The new branch is stacked on top of the current branch, which must be trunk or a branch managed by gt.
+Use --track to start managing an untracked current branch (see 'gt track') before stacking on it.
+Use --insert to slot the new branch between the current branch and its children: the children are moved onto the new branch and restacked. If that stops on a conflict, 'gt abort' moves the children back and keeps the new branch.`,
 	Args:  cobra.MaximumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		// Check if we're on trunk branch and load config
-		onTrunk, cfg, err := isOnTrunkBranch()
-		if err != nil {
-			return err
-		}
-		if !onTrunk {
-			return fmt.Errorf("create command can only be run on trunk branch (%s)", cfg.TrunkBranch)
-		}
+	RunE:  recordOperation(runCreate),
+}
 
-		// Get commit message from positional arg or -m flag
-		var commitMessage string
-		if len(args) > 0 {
-			// Positional argument takes precedence
-			commitMessage = args[0]
-		} else if createMessage != "" {
-			// Fall back to -m flag
-			commitMessage = createMessage
-		} else {
-			return fmt.Errorf("commit message is required (provide as argument or via -m flag)")
-		}
+func runCreate(g git.Git, cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
 
-		// Generate branch name from commit message
-		branchName := sanitizeBranchName(commitMessage)
+	// The current branch becomes the parent of the new branch
+	parentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+	if parentBranch == "HEAD" {
+		return fmt.Errorf("cannot create a branch from a detached HEAD")
+	}
 
-		// Check if branch already exists
-		localExists, remoteExists, err := branchExists(branchName)
-		if err != nil {
-			return fmt.Errorf("failed to check if branch exists: %w", err)
+	if _, managed := cfg.ManagedBranches[parentBranch]; !managed && parentBranch != cfg.TrunkBranch {
+		if !createTrack {
+			return fmt.Errorf("branch '%s' is not tracked by gt (use --track to track it first)", parentBranch)
 		}
-		if localExists {
-			return fmt.Errorf("branch '%s' already exists locally", branchName)
+		grandparent, err := inferParent(g, cfg, parentBranch)
+		if err != nil {
+			return err
 		}
-		if remoteExists {
-			return fmt.Errorf("branch '%s' already exists on origin", branchName)
+		if err := trackBranch(g, cfg, parentBranch, grandparent); err != nil {
+			return err
 		}
+		fmt.Printf("Tracking branch '%s' on top of '%s'\n", parentBranch, grandparent)
+	}
 
-		// Stage files if -a flag is used
-		if createAll {
-			if err := stageAllFiles(); err != nil {
-				return err
-			}
-		}
+	// Get commit message from positional arg or -m flag
+	var commitMessage string
+	if len(args) > 0 {
+		// Positional argument takes precedence
+		commitMessage = args[0]
+	} else if createMessage != "" {
+		// Fall back to -m flag
+		commitMessage = createMessage
+	} else {
+		return fmt.Errorf("commit message is required (provide as argument or via -m flag)")
+	}
+
+	// Generate branch name from commit message
+	branchName := sanitizeBranchName(commitMessage)
 
-		// Create and checkout the new branch first (before committing)
-		if err := createBranch(branchName); err != nil {
+	// Check if branch already exists
+	localExists, remoteExists, err := branchExists(g, branchName)
+	if err != nil {
+		return fmt.Errorf("failed to check if branch exists: %w", err)
+	}
+	if localExists {
+		return fmt.Errorf("branch '%s' already exists locally", branchName)
+	}
+	if remoteExists {
+		return fmt.Errorf("branch '%s' already exists on origin", branchName)
+	}
+
+	// Stage files if -a flag is used
+	if createAll {
+		if err := stageAllFiles(g); err != nil {
 			return err
 		}
+	}
 
-		// Create the commit on the new branch
-		if err := createCommit(commitMessage); err != nil {
+	// Inserting moves the current branch's children onto the new branch, which
+	// restacks them, so the working tree has to be clean apart from the commit
+	children := childrenOf(cfg, parentBranch)
+	insertBelow := createInsert && len(children) > 0
+	if insertBelow {
+		if err := ensureNoOperationInProgress(); err != nil {
 			return err
 		}
-
-		// Add this branch as a managed branch in config
-		cfg.ManagedBranches[branchName] = config.Branch{
-			Name:        branchName,
-			Parent:      cfg.TrunkBranch,
-			Description: commitMessage,
+		if hasUnstagedChanges(g) {
+			return fmt.Errorf("commit, stage or stash your other changes before inserting a branch; its children have to be restacked")
 		}
+	}
 
-		if err := cfg.Save(); err != nil {
-			return fmt.Errorf("failed to save config: %w", err)
-		}
+	// Record the parent commit the new branch is based on
+	parentSHA, err := revParse(g, "HEAD")
+	if err != nil {
+		return err
+	}
+
+	// Create and checkout the new branch first (before committing)
+	if err := createBranch(g, branchName); err != nil {
+		return err
+	}
+
+	// Create the commit on the new branch
+	if err := createCommit(g, commitMessage); err != nil {
+		return err
+	}
+
+	// Add this branch as a managed branch in config
+	cfg.ManagedBranches[branchName] = config.Branch{
+		Name:        branchName,
+		Parent:      parentBranch,
+		Description: commitMessage,
+		ParentSHA:   parentSHA,
+	}
 
-		fmt.Printf("Created branch '%s' with commit: %s\n", branchName, commitMessage)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Created branch '%s' on top of '%s' with commit: %s\n", branchName, parentBranch, commitMessage)
+
+	if !insertBelow {
 		return nil
-	},
+	}
+
+	// Snapshot once the new branch and its commit exist, so that 'gt abort'
+	// only undoes moving the children and keeps the staged work
+	before, err := takeSnapshot(g)
+	if err != nil {
+		return err
+	}
+	descendants := []string{}
+	for _, child := range children {
+		if err := reparentBranch(g, cfg, child, branchName); err != nil {
+			return err
+		}
+		descendants = append(descendants, child)
+		descendants = append(descendants, descendantsOf(cfg, child)...)
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if err := startRestackFrom(g, cfg, before, "create", descendants, branchName); err != nil {
+		return err
+	}
+	fmt.Printf("Restacked %d branch(es) onto '%s'\n", len(descendants), branchName)
+	return nil
 }
 
 func init() {
 	createCmd.Flags().BoolVarP(&createAll, "all", "a", false, "Stage all changes before committing")
 	createCmd.Flags().StringVarP(&createMessage, "message", "m", "", "Commit message (used to generate branch name)")
+	createCmd.Flags().BoolVar(&createInsert, "insert", false, "Move the current branch's children onto the new branch")
+	createCmd.Flags().BoolVar(&createTrack, "track", false, "Track the current branch, inferring its parent, if it is not managed yet")
 }
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+func TestAbortCreateInsertKeepsNewBranch(t *testing.T) {
+	useScratchRepo(t)
+	rebaseDir := t.TempDir()
+
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+	})
+	setParentSHA(cfg, "b", "atip")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	g := git.NewFake()
+	g.On("rev-parse --abbrev-ref HEAD", "a")
+	g.Fail("rev-parse --verify inserted")
+	g.Fail("remote get-url origin")
+	g.On("rev-parse --verify HEAD^{commit}", "atip")
+	g.On("rev-parse --verify inserted^{commit}", "itip")
+	g.On("for-each-ref --format=%(refname:short) %(objectname) refs/heads", "a atip\nb btip\ninserted itip\nmain maintip")
+	g.On("rev-parse --path-format=absolute --git-path rebase-merge", rebaseDir)
+	g.Fail("rebase --onto itip atip b")
+
+	createInsert = true
+	defer func() { createInsert = false }()
+	if err := runCreate(g, nil, []string{"inserted"}); err == nil {
+		t.Fatal("Expected runCreate to stop on the conflict")
+	}
+
+	op, err := config.LoadOperation()
+	if err != nil || op == nil {
+		t.Fatalf("Expected an operation in progress, got %v (err: %v)", op, err)
+	}
+	if _, ok := op.OriginalSHAs["inserted"]; ok {
+		t.Errorf("Expected the new branch to be left out of the rollback, got %v", op.OriginalSHAs)
+	}
+
+	if err := runAbort(g, nil, nil); err != nil {
+		t.Fatalf("runAbort returned error: %v", err)
+	}
+	if g.Called("branch -D inserted") {
+		t.Error("Expected abort to keep the new branch and its commit")
+	}
+	if !g.Called("update-ref refs/heads/b btip") {
+		t.Errorf("Expected 'b' to be reset, got calls: %v", g.Calls)
+	}
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if b := saved.ManagedBranches["b"]; b.Parent != "a" {
+		t.Errorf("Expected 'b' back on 'a', got %+v", b)
+	}
+	if inserted, ok := saved.ManagedBranches["inserted"]; !ok || inserted.Parent != "a" {
+		t.Errorf("Expected 'inserted' to stay tracked on 'a', got %+v", inserted)
+	}
+}
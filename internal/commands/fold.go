@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var (
+	foldKeep bool
+)
+
+var foldCmd = &cobra.Command{
+	Use:   "fold",
+	Short: "Fold the current branch into its parent",
+	Long: `Fold the current branch into its parent. The parent branch is moved forward to include the current branch's commits, the current branch's children are stacked on the parent, and the current branch is deleted.
+With --keep the current branch's name is kept instead: it takes the parent's place in the stack and the parent branch is deleted.
+Branches stacked on the folded result are restacked. Will not fold into trunk.`,
+	Args: cobra.NoArgs,
+	RunE: recordOperation(runFold),
+}
+
+func runFold(g git.Git, cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+	branch, managed := cfg.ManagedBranches[currentBranch]
+	if !managed {
+		return fmt.Errorf("branch '%s' is not tracked by gt", currentBranch)
+	}
+	if branch.Parent == cfg.TrunkBranch {
+		return fmt.Errorf("cannot fold '%s' into trunk branch (%s)", currentBranch, cfg.TrunkBranch)
+	}
+	if _, managed := cfg.ManagedBranches[branch.Parent]; !managed {
+		return fmt.Errorf("parent '%s' is not tracked by gt", branch.Parent)
+	}
+
+	if err := ensureNoOperationInProgress(); err != nil {
+		return err
+	}
+	clean, err := isWorkingTreeClean(g)
+	if err != nil {
+		return err
+	}
+	if !clean {
+		return fmt.Errorf("commit or stash your changes before folding a branch")
+	}
+	if needsRestack(g, cfg, currentBranch) {
+		return fmt.Errorf("branch '%s' is not based on its parent's latest commit; run 'gt restack' first", currentBranch)
+	}
+
+	before, err := takeSnapshot(g)
+	if err != nil {
+		return err
+	}
+
+	parentBranch := branch.Parent
+	result, err := foldBranch(g, cfg, currentBranch, foldKeep)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if err := startRestackFrom(g, cfg, before, "fold", descendantsOf(cfg, result), result); err != nil {
+		return err
+	}
+
+	fmt.Printf("Folded '%s' into '%s' as '%s'\n", currentBranch, parentBranch, result)
+	return nil
+}
+
+// foldBranch combines a branch with its parent, which it must be based on the
+// tip of. Unless keep is set the parent is fast-forwarded to the branch and the
+// branch is deleted; with keep the branch takes the parent's place in the stack
+// and the parent is deleted. Returns the name of the combined branch, which is
+// left checked out.
+func foldBranch(g git.Git, cfg *config.Config, branchName string, keep bool) (string, error) {
+	branch := cfg.ManagedBranches[branchName]
+	parentBranch := branch.Parent
+
+	if !keep {
+		tip, err := revParse(g, branchName)
+		if err != nil {
+			return "", err
+		}
+		if err := resetBranchTo(g, parentBranch, tip); err != nil {
+			return "", err
+		}
+		if _, err := reparentChildren(g, cfg, branchName, parentBranch); err != nil {
+			return "", err
+		}
+		if err := checkoutBranch(g, parentBranch); err != nil {
+			return "", err
+		}
+		if err := deleteBranch(g, branchName); err != nil {
+			return "", err
+		}
+		delete(cfg.ManagedBranches, branchName)
+		return parentBranch, nil
+	}
+
+	// The branch's siblings move onto it, and it moves to where the parent was
+	for _, sibling := range childrenOf(cfg, parentBranch) {
+		if sibling == branchName {
+			continue
+		}
+		if err := reparentBranch(g, cfg, sibling, branchName); err != nil {
+			return "", err
+		}
+	}
+
+	parentBase, err := branchBase(g, cfg, parentBranch)
+	if err != nil {
+		return "", fmt.Errorf("failed to find base of '%s': %w", parentBranch, err)
+	}
+	branch.Parent = cfg.ManagedBranches[parentBranch].Parent
+	branch.ParentSHA = parentBase
+	cfg.ManagedBranches[branchName] = branch
+
+	if err := deleteBranch(g, parentBranch); err != nil {
+		return "", err
+	}
+	delete(cfg.ManagedBranches, parentBranch)
+	return branchName, nil
+}
+
+func init() {
+	foldCmd.Flags().BoolVar(&foldKeep, "keep", false, "Keep the current branch's name instead of the parent's")
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+func TestFoldBranchIntoParent(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+		"s": "a",
+	})
+	setParentSHA(cfg, "c", "btip")
+
+	g := git.NewFake()
+	g.On("rev-parse --verify b^{commit}", "btip")
+
+	result, err := foldBranch(g, cfg, "b", false)
+	if err != nil {
+		t.Fatalf("foldBranch returned error: %v", err)
+	}
+
+	if result != "a" {
+		t.Errorf("Expected folded branch to be 'a', got '%s'", result)
+	}
+	if _, ok := cfg.ManagedBranches["b"]; ok {
+		t.Error("Expected 'b' to be removed from managed branches")
+	}
+	if c := cfg.ManagedBranches["c"]; c.Parent != "a" || c.ParentSHA != "btip" {
+		t.Errorf("Expected 'c' on 'a' from 'btip', got %+v", c)
+	}
+	for _, call := range []string{"update-ref refs/heads/a btip", "checkout a", "branch -D b"} {
+		if !g.Called(call) {
+			t.Errorf("Expected '%s', got calls: %v", call, g.Calls)
+		}
+	}
+}
+
+func TestFoldBranchKeepingName(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+		"s": "a",
+	})
+	setParentSHA(cfg, "a", "mainbase")
+	setParentSHA(cfg, "s", "atip")
+
+	g := git.NewFake()
+
+	result, err := foldBranch(g, cfg, "b", true)
+	if err != nil {
+		t.Fatalf("foldBranch returned error: %v", err)
+	}
+
+	if result != "b" {
+		t.Errorf("Expected folded branch to be 'b', got '%s'", result)
+	}
+	if _, ok := cfg.ManagedBranches["a"]; ok {
+		t.Error("Expected 'a' to be removed from managed branches")
+	}
+	if b := cfg.ManagedBranches["b"]; b.Parent != "main" || b.ParentSHA != "mainbase" {
+		t.Errorf("Expected 'b' to take the place of 'a', got %+v", b)
+	}
+	if s := cfg.ManagedBranches["s"]; s.Parent != "b" || s.ParentSHA != "atip" {
+		t.Errorf("Expected sibling 's' on 'b' from 'atip', got %+v", s)
+	}
+	if cfg.ManagedBranches["c"].Parent != "b" {
+		t.Errorf("Expected 'c' to stay on 'b', got %+v", cfg.ManagedBranches["c"])
+	}
+	if !g.Called("branch -D a") {
+		t.Errorf("Expected 'a' to be deleted, got calls: %v", g.Calls)
+	}
+}
@@ -2,11 +2,12 @@ package commands
 
 import (
 	"fmt"
-	"os/exec"
+	"os"
 	"regexp"
 	"strings"
 
 	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
 )
 
 const (
@@ -14,24 +15,23 @@ const (
 )
 
 // getCurrentBranch returns the name of the current git branch
-func getCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	output, err := cmd.Output()
+func getCurrentBranch(g git.Git) (string, error) {
+	output, err := g.Run("rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return output, nil
 }
 
 // isOnTrunkBranch checks if the current branch is the trunk branch
 // Returns: (isOnTrunk, config, error)
-func isOnTrunkBranch() (bool, *config.Config, error) {
+func isOnTrunkBranch(g git.Git) (bool, *config.Config, error) {
 	cfg, err := config.Load()
 	if err != nil {
 		return false, nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	currentBranch, err := getCurrentBranch()
+	currentBranch, err := getCurrentBranch(g)
 	if err != nil {
 		return false, nil, err
 	}
@@ -40,26 +40,24 @@ func isOnTrunkBranch() (bool, *config.Config, error) {
 }
 
 // branchExists checks if a branch exists locally or remotely
-func branchExists(branchName string) (bool, bool, error) {
+func branchExists(g git.Git, branchName string) (bool, bool, error) {
 	// Check local branches
-	localCmd := exec.Command("git", "rev-parse", "--verify", branchName)
-	localExists := localCmd.Run() == nil
+	_, err := g.Run("rev-parse", "--verify", branchName)
+	localExists := err == nil
 
 	// Check if remote 'origin' exists
-	remoteCheckCmd := exec.Command("git", "remote", "get-url", "origin")
-	if err := remoteCheckCmd.Run(); err != nil {
+	if _, err := g.Run("remote", "get-url", "origin"); err != nil {
 		// No remote configured, that's ok - just return local status
 		return localExists, false, nil
 	}
 
 	// Check remote branches
-	remoteCmd := exec.Command("git", "ls-remote", "--heads", "origin", branchName)
-	remoteOutput, err := remoteCmd.CombinedOutput()
+	remoteOutput, err := g.Run("ls-remote", "--heads", "origin", branchName)
 	if err != nil {
 		// Report errors when remote exists but ls-remote fails (network, auth, etc.)
 		return localExists, false, fmt.Errorf("failed to check remote branches: %w", err)
 	}
-	remoteExists := len(strings.TrimSpace(string(remoteOutput))) > 0
+	remoteExists := len(remoteOutput) > 0
 
 	return localExists, remoteExists, nil
 }
@@ -88,66 +86,219 @@ func sanitizeBranchName(message string) string {
 }
 
 // stageAllFiles stages all changes (equivalent to git add -A)
-func stageAllFiles() error {
-	cmd := exec.Command("git", "add", "-A")
-	if err := cmd.Run(); err != nil {
+func stageAllFiles(g git.Git) error {
+	if _, err := g.Run("add", "-A"); err != nil {
 		return fmt.Errorf("failed to stage files: %w", err)
 	}
 	return nil
 }
 
+// stagePatch lets the user interactively choose hunks to stage (git add --patch)
+func stagePatch(g git.Git) error {
+	if err := g.RunInteractive("add", "--patch"); err != nil {
+		return fmt.Errorf("failed to stage hunks: %w", err)
+	}
+	return nil
+}
+
 // createCommit creates a commit with the given message
-func createCommit(message string) error {
-	cmd := exec.Command("git", "commit", "-m", message)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to create commit: %w\nOutput: %s", err, string(output))
+func createCommit(g git.Git, message string) error {
+	if _, err := g.Run("commit", "-m", message); err != nil {
+		return fmt.Errorf("failed to create commit: %w", err)
 	}
 	return nil
 }
 
 // amendCommit amends the most recent commit without editing the message
-func amendCommit() error {
-	cmd := exec.Command("git", "commit", "--amend", "--no-edit")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to amend commit: %w\nOutput: %s", err, string(output))
+func amendCommit(g git.Git) error {
+	if _, err := g.Run("commit", "--amend", "--no-edit"); err != nil {
+		return fmt.Errorf("failed to amend commit: %w", err)
+	}
+	return nil
+}
+
+// amendCommitMessage amends the most recent commit, replacing its message
+func amendCommitMessage(g git.Git, message string) error {
+	if _, err := g.Run("commit", "--amend", "-m", message); err != nil {
+		return fmt.Errorf("failed to amend commit: %w", err)
 	}
 	return nil
 }
 
 // createBranch creates a new branch with the given name
-func createBranch(branchName string) error {
-	cmd := exec.Command("git", "checkout", "-b", branchName)
-	if err := cmd.Run(); err != nil {
+func createBranch(g git.Git, branchName string) error {
+	if _, err := g.Run("checkout", "-b", branchName); err != nil {
 		return fmt.Errorf("failed to create branch: %w", err)
 	}
 	return nil
 }
 
 // resetLastCommit resets the last commit while keeping the changes in the working directory
-func resetLastCommit() error {
-	cmd := exec.Command("git", "reset", "--soft", "HEAD~1")
-	if err := cmd.Run(); err != nil {
+func resetLastCommit(g git.Git) error {
+	if _, err := g.Run("reset", "--soft", "HEAD~1"); err != nil {
 		return fmt.Errorf("failed to reset commit: %w", err)
 	}
 	return nil
 }
 
 // checkoutBranch switches to the specified branch
-func checkoutBranch(branchName string) error {
-	cmd := exec.Command("git", "checkout", branchName)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to checkout branch: %w", err)
+func checkoutBranch(g git.Git, branchName string) error {
+	if _, err := g.Run("checkout", branchName); err != nil {
+		return fmt.Errorf("failed to checkout branch '%s': %w", branchName, err)
 	}
 	return nil
 }
 
 // deleteBranch deletes the specified branch
-func deleteBranch(branchName string) error {
-	cmd := exec.Command("git", "branch", "-D", branchName)
-	if err := cmd.Run(); err != nil {
+func deleteBranch(g git.Git, branchName string) error {
+	if _, err := g.Run("branch", "-D", branchName); err != nil {
 		return fmt.Errorf("failed to delete branch: %w", err)
 	}
 	return nil
 }
+
+// revParse resolves a revision (branch name, ref, etc.) to a full commit SHA
+func revParse(g git.Git, rev string) (string, error) {
+	output, err := g.Run("rev-parse", "--verify", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve '%s': %w", rev, err)
+	}
+	return output, nil
+}
+
+// mergeBase returns the best common ancestor of two revisions
+func mergeBase(g git.Git, a, b string) (string, error) {
+	output, err := g.Run("merge-base", a, b)
+	if err != nil {
+		return "", fmt.Errorf("failed to find merge base of '%s' and '%s': %w", a, b, err)
+	}
+	return output, nil
+}
+
+// rebaseOnto replays the commits of branch after oldBase on top of newBase
+func rebaseOnto(g git.Git, newBase, oldBase, branch string) error {
+	if _, err := g.Run("rebase", "--onto", newBase, oldBase, branch); err != nil {
+		return fmt.Errorf("failed to rebase branch '%s': %w", branch, err)
+	}
+	return nil
+}
+
+// abortRebase aborts an in-progress rebase
+func abortRebase(g git.Git) error {
+	if _, err := g.Run("rebase", "--abort"); err != nil {
+		return fmt.Errorf("failed to abort rebase: %w", err)
+	}
+	return nil
+}
+
+// continueRebase continues an in-progress rebase without opening an editor
+func continueRebase(g git.Git) error {
+	if _, err := g.Run("-c", "core.editor=true", "rebase", "--continue"); err != nil {
+		return fmt.Errorf("failed to continue rebase: %w", err)
+	}
+	return nil
+}
+
+// isRebaseInProgress checks whether git is in the middle of a rebase
+func isRebaseInProgress(g git.Git) bool {
+	for _, stateDir := range []string{"rebase-merge", "rebase-apply"} {
+		output, err := g.Run("rev-parse", "--path-format=absolute", "--git-path", stateDir)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(output); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isAncestor checks whether ancestor is reachable from descendant
+func isAncestor(g git.Git, ancestor, descendant string) bool {
+	_, err := g.Run("merge-base", "--is-ancestor", ancestor, descendant)
+	return err == nil
+}
+
+// resetBranchTo points a local branch at the given commit without touching the working tree
+func resetBranchTo(g git.Git, branchName, sha string) error {
+	if _, err := g.Run("update-ref", "refs/heads/"+branchName, sha); err != nil {
+		return fmt.Errorf("failed to reset branch '%s': %w", branchName, err)
+	}
+	return nil
+}
+
+// authorEnv returns the environment that makes git record the author of the
+// given commit, with its original date, on a new commit
+func authorEnv(g git.Git, commit string) ([]string, error) {
+	author, err := g.Run("log", "-1", "--format=%an%x00%ae%x00%ad", "--date=raw", commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read author of '%s': %w", commit, err)
+	}
+	fields := strings.Split(author, "\x00")
+	if len(fields) != 3 {
+		return nil, nil
+	}
+	return []string{"GIT_AUTHOR_NAME=" + fields[0], "GIT_AUTHOR_EMAIL=" + fields[1], "GIT_AUTHOR_DATE=" + fields[2]}, nil
+}
+
+// detachHead detaches HEAD at the current commit
+func detachHead(g git.Git) error {
+	if _, err := g.Run("checkout", "--detach"); err != nil {
+		return fmt.Errorf("failed to detach HEAD: %w", err)
+	}
+	return nil
+}
+
+// listBranchSHAs returns the commit each local branch points at
+func listBranchSHAs(g git.Git) (map[string]string, error) {
+	output, err := g.Run("for-each-ref", "--format=%(refname:short) %(objectname)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	branches := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			branches[fields[0]] = fields[1]
+		}
+	}
+	return branches, nil
+}
+
+// setBranchSHAs moves, creates or deletes (empty SHA) local branches in a
+// single atomic ref transaction. current holds the expected existing tips so
+// the transaction fails if a branch was changed concurrently.
+func setBranchSHAs(g git.Git, target, current map[string]string) error {
+	var input strings.Builder
+	input.WriteString("start\n")
+	for name, sha := range target {
+		ref := "refs/heads/" + name
+		oldSHA, exists := current[name]
+		switch {
+		case sha == "" && exists:
+			fmt.Fprintf(&input, "delete %s %s\n", ref, oldSHA)
+		case sha == "":
+			continue
+		case exists:
+			fmt.Fprintf(&input, "update %s %s %s\n", ref, sha, oldSHA)
+		default:
+			fmt.Fprintf(&input, "create %s %s\n", ref, sha)
+		}
+	}
+	input.WriteString("commit\n")
+
+	if _, err := g.RunWithInput(input.String(), "update-ref", "--stdin"); err != nil {
+		return fmt.Errorf("failed to update branches: %w", err)
+	}
+	return nil
+}
+
+// isWorkingTreeClean checks that there are no uncommitted changes to tracked files
+func isWorkingTreeClean(g git.Git) (bool, error) {
+	output, err := g.Run("status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return false, fmt.Errorf("failed to get status: %w", err)
+	}
+	return len(output) == 0, nil
+}
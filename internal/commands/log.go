@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the stack of managed branches",
+	Long:  `Show trunk and the tree of branches managed by gt as a graph, with each branch's commits. Branches are annotated when they are checked out, need restacking, exist on origin, or are missing locally.`,
+	Args:  cobra.NoArgs,
+	RunE: withGit(func(g git.Git, cmd *cobra.Command, args []string) error {
+		return runLog(g, false)
+	}),
+}
+
+var logShortCmd = &cobra.Command{
+	Use:   "short",
+	Short: "Show the stack of managed branches without commits",
+	Long:  `Show trunk and the tree of branches managed by gt as a graph, one line per branch.`,
+	Args:  cobra.NoArgs,
+	RunE: withGit(func(g git.Git, cmd *cobra.Command, args []string) error {
+		return runLog(g, true)
+	}),
+}
+
+// branchStatus describes a managed branch for display in the stack graph
+type branchStatus struct {
+	// Commits holds "<short sha> <subject>" lines, newest first
+	Commits      []string
+	NeedsRestack bool
+	OnOrigin     bool
+	Missing      bool
+}
+
+func runLog(g git.Git, short bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+
+	statuses := make(map[string]branchStatus)
+	for name := range cfg.ManagedBranches {
+		statuses[name] = getBranchStatus(g, cfg, name, short)
+	}
+
+	graph, err := renderStack(cfg, statuses, currentBranch, short)
+	if err != nil {
+		return err
+	}
+	fmt.Print(graph)
+	return nil
+}
+
+// getBranchStatus collects the display information for a managed branch.
+// Commits are not listed in short mode.
+func getBranchStatus(g git.Git, cfg *config.Config, branchName string, short bool) branchStatus {
+	status := branchStatus{}
+	if _, err := revParse(g, branchName); err != nil {
+		status.Missing = true
+		return status
+	}
+
+	status.NeedsRestack = needsRestack(g, cfg, branchName)
+	_, err := g.Run("rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branchName)
+	status.OnOrigin = err == nil
+
+	if !short {
+		if base, err := branchBase(g, cfg, branchName); err == nil {
+			if output, err := g.Run("log", "--format=%h %s", base+".."+branchName); err == nil && output != "" {
+				status.Commits = strings.Split(output, "\n")
+			}
+		}
+	}
+	return status
+}
+
+// branchBase returns the commit a managed branch's own commits start after:
+// its recorded parent base, or the merge base with its parent if none is recorded
+func branchBase(g git.Git, cfg *config.Config, branchName string) (string, error) {
+	branch := cfg.ManagedBranches[branchName]
+	if branch.ParentSHA != "" {
+		return branch.ParentSHA, nil
+	}
+	return mergeBase(g, branch.Parent, branchName)
+}
+
+// needsRestack reports whether a managed branch is no longer based on the tip of its parent
+func needsRestack(g git.Git, cfg *config.Config, branchName string) bool {
+	branch := cfg.ManagedBranches[branchName]
+	parentTip, err := revParse(g, branch.Parent)
+	if err != nil {
+		return false
+	}
+	if branch.ParentSHA != "" {
+		return branch.ParentSHA != parentTip
+	}
+	return !isAncestor(g, parentTip, branchName)
+}
+
+// renderStack draws trunk and the managed branches as an ASCII tree. Returns
+// an error if the parent links contain a cycle, as no tree can be drawn.
+func renderStack(cfg *config.Config, statuses map[string]branchStatus, currentBranch string, short bool) (string, error) {
+	order, err := topoOrder(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+
+	marker := "◯"
+	if currentBranch == cfg.TrunkBranch {
+		marker = "◉"
+	}
+	out.WriteString(marker + " " + cfg.TrunkBranch)
+	if currentBranch == cfg.TrunkBranch {
+		out.WriteString(" (current)")
+	}
+	out.WriteString("\n")
+
+	// Branches whose parent is not managed are shown directly under trunk
+	roots := []string{}
+	for _, name := range order {
+		parent := cfg.ManagedBranches[name].Parent
+		if _, managed := cfg.ManagedBranches[parent]; !managed {
+			roots = append(roots, name)
+		}
+	}
+
+	for i, name := range roots {
+		renderBranch(&out, cfg, statuses, currentBranch, short, name, "", i == len(roots)-1)
+	}
+	return out.String(), nil
+}
+
+// renderBranch draws a branch, its commits and its children below the given prefix
+func renderBranch(out *strings.Builder, cfg *config.Config, statuses map[string]branchStatus, currentBranch string, short bool, name, prefix string, last bool) {
+	branch := cfg.ManagedBranches[name]
+	status := statuses[name]
+
+	connector, childPrefix := "├─", prefix+"│ "
+	if last {
+		connector, childPrefix = "└─", prefix+"  "
+	}
+
+	marker := "◯"
+	annotations := []string{}
+	if name == currentBranch {
+		marker = "◉"
+		annotations = append(annotations, "current")
+	}
+	if branch.Parent != cfg.TrunkBranch {
+		if _, managed := cfg.ManagedBranches[branch.Parent]; !managed {
+			annotations = append(annotations, "parent: "+branch.Parent)
+		}
+	}
+	if status.Missing {
+		annotations = append(annotations, "missing locally")
+	}
+	if status.NeedsRestack {
+		annotations = append(annotations, "needs restack")
+	}
+	if status.OnOrigin {
+		annotations = append(annotations, "on origin")
+	}
+
+	out.WriteString(prefix + connector + marker + " " + name)
+	if len(annotations) > 0 {
+		out.WriteString(" (" + strings.Join(annotations, ", ") + ")")
+	}
+	out.WriteString("\n")
+
+	children := childrenOf(cfg, name)
+	if !short {
+		commitPrefix := childPrefix + "  "
+		if len(children) > 0 {
+			commitPrefix = childPrefix + "│ "
+		}
+		for _, commit := range status.Commits {
+			out.WriteString(commitPrefix + commit + "\n")
+		}
+	}
+
+	for i, child := range children {
+		renderBranch(out, cfg, statuses, currentBranch, short, child, childPrefix, i == len(children)-1)
+	}
+}
+
+func init() {
+	logCmd.AddCommand(logShortCmd)
+}
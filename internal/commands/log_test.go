@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+func TestRenderStack(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "a",
+		"d": "main",
+	})
+	statuses := map[string]branchStatus{
+		"a": {Commits: []string{"1111111 Add a"}, OnOrigin: true},
+		"b": {Commits: []string{"2222222 Add b"}, NeedsRestack: true},
+		"c": {Commits: []string{"3333333 Add c"}},
+		"d": {Missing: true},
+	}
+
+	expected := `◯ main
+├─◯ a (on origin)
+│ │ 1111111 Add a
+│ ├─◉ b (current, needs restack)
+│ │   2222222 Add b
+│ └─◯ c
+│     3333333 Add c
+└─◯ d (missing locally)
+`
+	if got, err := renderStack(cfg, statuses, "b", false); err != nil || got != expected {
+		t.Errorf("renderStack() =\n%s\nwant:\n%s", got, expected)
+	}
+
+	expectedShort := `◉ main (current)
+├─◯ a (on origin)
+│ ├─◯ b (needs restack)
+│ └─◯ c
+└─◯ d (missing locally)
+`
+	if got, err := renderStack(cfg, statuses, "main", true); err != nil || got != expectedShort {
+		t.Errorf("renderStack() short =\n%s\nwant:\n%s", got, expectedShort)
+	}
+}
+
+func TestLogReportsParentCycle(t *testing.T) {
+	useScratchRepo(t)
+
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "c",
+		"c": "b",
+	})
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	err := runLog(git.NewFake(), true)
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Expected the parent cycle to be reported, got %v", err)
+	}
+}
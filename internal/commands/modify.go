@@ -4,46 +4,84 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/git"
 )
 
 var (
-	modifyAll bool
+	modifyAll       bool
+	modifyCommit    bool
+	modifyMessage   string
+	modifyPatch     bool
+	modifyNoRestack bool
 )
 
 var modifyCmd = &cobra.Command{
 	Use:   "modify",
-	Short: "Amend the current commit",
-	Long:  `Amend the current commit. This allows you to modify the most recent commit on the current branch. Will not run on trunk branch.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get current branch
-		currentBranch, err := getCurrentBranch()
-		if err != nil {
-			return err
-		}
+	Short: "Amend the current commit or add a new one",
+	Long:  `Amend the current commit. This allows you to modify the most recent commit on the current branch, or with --commit add a new commit to it instead. --patch lets you pick the hunks to stage interactively first. Afterwards, every branch stacked on top of the current one is restacked unless --no-restack is given; restacking needs any changes that are not being committed to be stashed first. If a rebase stops on a conflict, resolve it and run 'gt continue', or run 'gt abort' to roll back. Will not run on trunk branch.`,
+	RunE:  recordOperation(runModify),
+}
 
-		// Check for detached HEAD
-		if currentBranch == "HEAD" {
-			return fmt.Errorf("Error: detached HEAD")
-		}
+func runModify(g git.Git, cmd *cobra.Command, args []string) error {
+	// Get current branch
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
 
-		// Check if we're on trunk branch
-		onTrunk, cfg, err := isOnTrunkBranch()
-		if err != nil {
+	// Check for detached HEAD
+	if currentBranch == "HEAD" {
+		return fmt.Errorf("Error: detached HEAD")
+	}
+
+	// Check if we're on trunk branch
+	onTrunk, cfg, err := isOnTrunkBranch(g)
+	if err != nil {
+		return err
+	}
+	if onTrunk {
+		return fmt.Errorf("Error: gt modify cannot be run on %s", cfg.TrunkBranch)
+	}
+
+	if err := ensureNoOperationInProgress(); err != nil {
+		return err
+	}
+
+	if modifyAll && modifyPatch {
+		return fmt.Errorf("--all and --patch cannot be used together")
+	}
+	if modifyCommit && modifyMessage == "" {
+		return fmt.Errorf("a commit message is required with --commit; pass it with -m")
+	}
+
+	// Stage all files if -a flag is used
+	if modifyAll {
+		if err := stageAllFiles(g); err != nil {
 			return err
 		}
-		if onTrunk {
-			return fmt.Errorf("Error: gt modify cannot be run on %s", cfg.TrunkBranch)
-		}
+	}
 
-		// Stage all files if -a flag is used
-		if modifyAll {
-			if err := stageAllFiles(); err != nil {
-				return err
-			}
+	// Let the user pick hunks to stage if --patch is used
+	if modifyPatch {
+		if err := stagePatch(g); err != nil {
+			return err
 		}
+	}
 
+	// Restacking rebases the branches above, which git refuses to do with
+	// unstaged changes; check before the commit is rewritten, not after
+	if !modifyNoRestack && len(descendantsOf(cfg, currentBranch)) > 0 && hasUnstagedChanges(g) {
+		return fmt.Errorf("commit, stage or stash your other changes first; the branches stacked on '%s' have to be restacked (or pass --no-restack)", currentBranch)
+	}
+
+	if modifyCommit {
+		if err := createCommit(g, modifyMessage); err != nil {
+			return err
+		}
+		fmt.Println("✔ gt modify: added a new commit")
+	} else {
 		// Check if branch exists on origin
-		_, remoteExists, err := branchExists(currentBranch)
+		_, remoteExists, err := branchExists(g, currentBranch)
 		if err != nil {
 			// If we can't check remote, just continue (might not have origin configured)
 			// Don't fail the command because of this
@@ -54,16 +92,30 @@ var modifyCmd = &cobra.Command{
 			fmt.Println()
 		}
 
-		// Amend the commit
-		if err := amendCommit(); err != nil {
+		// Amend the commit, replacing its message if -m was given
+		if modifyMessage != "" {
+			if err := amendCommitMessage(g, modifyMessage); err != nil {
+				return err
+			}
+		} else if err := amendCommit(g); err != nil {
 			return err
 		}
-
 		fmt.Println("✔ gt modify: amended latest commit")
+	}
+
+	if modifyNoRestack {
+		if count := len(descendantsOf(cfg, currentBranch)); count > 0 {
+			fmt.Printf("Skipped restacking %d branch(es) stacked on '%s'; run 'gt restack' later\n", count, currentBranch)
+		}
 		return nil
-	},
+	}
+	return restackDescendants(g, cfg, "modify", currentBranch)
 }
 
 func init() {
 	modifyCmd.Flags().BoolVarP(&modifyAll, "all", "a", false, "Stage all changes before amending")
+	modifyCmd.Flags().BoolVarP(&modifyCommit, "commit", "c", false, "Create a new commit instead of amending")
+	modifyCmd.Flags().StringVarP(&modifyMessage, "message", "m", "", "Commit message for the new or amended commit")
+	modifyCmd.Flags().BoolVarP(&modifyPatch, "patch", "p", false, "Interactively choose hunks to stage first")
+	modifyCmd.Flags().BoolVar(&modifyNoRestack, "no-restack", false, "Do not restack branches stacked on the current branch")
 }
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+func TestModifyRefusesUnstagedChangesBeforeAmending(t *testing.T) {
+	useScratchRepo(t)
+
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+	})
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	g := git.NewFake()
+	g.On("rev-parse --abbrev-ref HEAD", "a")
+	g.Fail("diff --quiet")
+
+	if err := runModify(g, nil, nil); err == nil {
+		t.Fatal("Expected runModify to refuse a dirty working tree")
+	}
+	if g.Called("commit --amend --no-edit") {
+		t.Error("Expected the commit to be left alone")
+	}
+
+	// Without branches to restack there is nothing to get in the way
+	g = git.NewFake()
+	g.On("rev-parse --abbrev-ref HEAD", "b")
+	g.Fail("diff --quiet")
+	g.Fail("remote get-url origin")
+
+	if err := runModify(g, nil, nil); err != nil {
+		t.Fatalf("runModify returned error: %v", err)
+	}
+	if !g.Called("commit --amend --no-edit") {
+		t.Errorf("Expected the commit to be amended, got calls: %v", g.Calls)
+	}
+}
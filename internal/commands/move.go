@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var (
+	moveOnto string
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move --onto <branch>",
+	Short: "Move the current branch onto another parent",
+	Long:  `Move the current branch, together with every branch stacked on it, onto another parent: trunk or a branch managed by gt. The branch's own commits are rebased onto the new parent and its descendants are restacked. Refuses to move a branch onto one of its own descendants. If a rebase stops on a conflict, resolve it and run 'gt continue', or run 'gt abort' to roll back.`,
+	Args:  cobra.NoArgs,
+	RunE:  recordOperation(runMove),
+}
+
+func runMove(g git.Git, cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+	branch, managed := cfg.ManagedBranches[currentBranch]
+	if !managed {
+		return fmt.Errorf("branch '%s' is not tracked by gt", currentBranch)
+	}
+
+	if err := validateMoveTarget(cfg, currentBranch, moveOnto); err != nil {
+		return err
+	}
+	if branch.Parent == moveOnto {
+		fmt.Printf("'%s' is already stacked on '%s'\n", currentBranch, moveOnto)
+		return nil
+	}
+	if _, err := revParse(g, moveOnto); err != nil {
+		return fmt.Errorf("branch '%s' does not exist locally", moveOnto)
+	}
+
+	if err := ensureNoOperationInProgress(); err != nil {
+		return err
+	}
+	clean, err := isWorkingTreeClean(g)
+	if err != nil {
+		return err
+	}
+	if !clean {
+		return fmt.Errorf("commit or stash your changes before moving a branch")
+	}
+
+	before, err := takeSnapshot(g)
+	if err != nil {
+		return err
+	}
+	if err := reparentBranch(g, cfg, currentBranch, moveOnto); err != nil {
+		return err
+	}
+	// Save the new parent first; 'gt continue' reloads the config from disk
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	branches := append([]string{currentBranch}, descendantsOf(cfg, currentBranch)...)
+	if err := startRestackFrom(g, cfg, before, "move", branches, currentBranch); err != nil {
+		return err
+	}
+
+	fmt.Printf("Moved '%s' onto '%s'\n", currentBranch, moveOnto)
+	return nil
+}
+
+// validateMoveTarget checks that a branch can be stacked on the target: it must
+// be trunk or a managed branch, and not the branch itself or one of its descendants
+func validateMoveTarget(cfg *config.Config, branchName, target string) error {
+	if target == branchName {
+		return fmt.Errorf("cannot move '%s' onto itself", branchName)
+	}
+	if _, managed := cfg.ManagedBranches[target]; !managed && target != cfg.TrunkBranch {
+		return fmt.Errorf("target '%s' must be trunk or a branch tracked by gt", target)
+	}
+	for _, descendant := range descendantsOf(cfg, branchName) {
+		if descendant == target {
+			return fmt.Errorf("cannot move '%s' onto '%s', which is stacked on top of it", branchName, target)
+		}
+	}
+	return nil
+}
+
+func init() {
+	moveCmd.Flags().StringVar(&moveOnto, "onto", "", "Branch to move the current branch onto")
+	moveCmd.MarkFlagRequired("onto")
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+func TestValidateMoveTarget(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+		"x": "main",
+	})
+
+	tests := []struct {
+		target  string
+		wantErr bool
+	}{
+		{"main", false},
+		{"x", false},
+		{"a", false},
+		{"b", true}, // itself
+		{"c", true}, // descendant
+		{"not-tracked", true},
+	}
+
+	for _, tt := range tests {
+		err := validateMoveTarget(cfg, "b", tt.target)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateMoveTarget(b, %s) error = %v; wantErr %v", tt.target, err, tt.wantErr)
+		}
+	}
+}
+
+// newConflictFake returns a fake repository with branches a, b, c and main,
+// with b checked out. A failing rebase leaves a rebase in progress until
+// rebaseDir is removed.
+func newConflictFake(rebaseDir string) *git.Fake {
+	g := git.NewFake()
+	g.On("rev-parse --abbrev-ref HEAD", "b")
+	g.On("for-each-ref --format=%(refname:short) %(objectname) refs/heads", "a atip\nb btip\nc ctip\nmain maintip")
+	for _, name := range []string{"a", "b", "c", "main"} {
+		g.On("rev-parse --verify "+name+"^{commit}", name+"tip")
+	}
+	g.On("rev-parse --path-format=absolute --git-path rebase-merge", rebaseDir)
+	return g
+}
+
+func TestMoveSavesNewParentBeforeConflict(t *testing.T) {
+	useScratchRepo(t)
+	rebaseDir := t.TempDir()
+
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "main",
+	})
+	setParentSHA(cfg, "b", "atip")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	g := newConflictFake(rebaseDir)
+	g.Fail("rebase --onto ctip atip b")
+
+	moveOnto = "c"
+	defer func() { moveOnto = "" }()
+	if err := runMove(g, nil, nil); err == nil {
+		t.Fatal("Expected runMove to stop on the conflict")
+	}
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if b := saved.ManagedBranches["b"]; b.Parent != "c" || b.ParentSHA != "atip" {
+		t.Errorf("Expected 'b' saved on 'c' from 'atip' while the rebase is stopped, got %+v", b)
+	}
+
+	// Resolve the conflict and continue
+	if err := os.Remove(rebaseDir); err != nil {
+		t.Fatalf("Failed to remove rebase dir: %v", err)
+	}
+	if err := runContinue(g, nil, nil); err != nil {
+		t.Fatalf("runContinue returned error: %v", err)
+	}
+
+	saved, err = config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if b := saved.ManagedBranches["b"]; b.Parent != "c" || b.ParentSHA != "ctip" {
+		t.Errorf("Expected 'b' on 'c' from 'ctip' after continuing, got %+v", b)
+	}
+}
+
+func TestMoveRefusesWhileOperationInProgress(t *testing.T) {
+	useScratchRepo(t)
+
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "main",
+	})
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	op := &config.Operation{Command: "modify", OriginalBranch: "a", OriginalConfig: cfg}
+	if err := op.Save(); err != nil {
+		t.Fatalf("Failed to save operation: %v", err)
+	}
+
+	moveOnto = "c"
+	defer func() { moveOnto = "" }()
+	if err := runMove(newConflictFake(t.TempDir()), nil, nil); err == nil {
+		t.Fatal("Expected runMove to refuse while another operation is in progress")
+	}
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if b := saved.ManagedBranches["b"]; b.Parent != "a" {
+		t.Errorf("Expected 'b' to stay on 'a', got %+v", b)
+	}
+}
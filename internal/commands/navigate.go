@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var upCmd = &cobra.Command{
+	Use:   "up [n]",
+	Short: "Check out a child of the current branch",
+	Long:  `Move n levels (default 1) up the stack by checking out a child of the current branch. If a branch has several children you are prompted to pick one.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: withGit(func(g git.Git, cmd *cobra.Command, args []string) error {
+		steps, err := parseSteps(args)
+		if err != nil {
+			return err
+		}
+		return navigate(g, func(cfg *config.Config, from string) (string, error) {
+			return walkUp(cfg, from, steps, chooseChild)
+		})
+	}),
+}
+
+var downCmd = &cobra.Command{
+	Use:   "down [n]",
+	Short: "Check out the parent of the current branch",
+	Long:  `Move n levels (default 1) down the stack by checking out the parent of the current branch, stopping at trunk.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: withGit(func(g git.Git, cmd *cobra.Command, args []string) error {
+		steps, err := parseSteps(args)
+		if err != nil {
+			return err
+		}
+		return navigate(g, func(cfg *config.Config, from string) (string, error) {
+			return walkDown(cfg, from, steps)
+		})
+	}),
+}
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Check out the top of the current stack",
+	Long:  `Check out the branch at the top of the current stack. If a branch along the way has several children you are prompted to pick one.`,
+	Args:  cobra.NoArgs,
+	RunE: withGit(func(g git.Git, cmd *cobra.Command, args []string) error {
+		return navigate(g, func(cfg *config.Config, from string) (string, error) {
+			return walkUp(cfg, from, -1, chooseChild)
+		})
+	}),
+}
+
+var bottomCmd = &cobra.Command{
+	Use:   "bottom",
+	Short: "Check out the bottom of the current stack",
+	Long:  `Check out the branch at the bottom of the current stack, i.e. the one directly on top of trunk.`,
+	Args:  cobra.NoArgs,
+	RunE: withGit(func(g git.Git, cmd *cobra.Command, args []string) error {
+		return navigate(g, func(cfg *config.Config, from string) (string, error) {
+			return walkToBottom(cfg, from)
+		})
+	}),
+}
+
+// parseSteps parses the optional step count argument of up/down
+func parseSteps(args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, nil
+	}
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps < 1 {
+		return 0, fmt.Errorf("invalid number of steps '%s'", args[0])
+	}
+	return steps, nil
+}
+
+// navigate resolves the target branch from the current one and checks it out
+func navigate(g git.Git, target func(cfg *config.Config, from string) (string, error)) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+	if currentBranch == "HEAD" {
+		return fmt.Errorf("cannot navigate the stack from a detached HEAD")
+	}
+	if _, managed := cfg.ManagedBranches[currentBranch]; !managed && currentBranch != cfg.TrunkBranch {
+		return fmt.Errorf("branch '%s' is not tracked by gt", currentBranch)
+	}
+
+	targetBranch, err := target(cfg, currentBranch)
+	if err != nil {
+		return err
+	}
+
+	if err := checkoutBranch(g, targetBranch); err != nil {
+		return err
+	}
+
+	fmt.Printf("Checked out '%s'\n", targetBranch)
+	return nil
+}
+
+// walkUp follows child links from a branch for the given number of steps, or
+// until the top of the stack if steps is negative. choose picks between
+// several children.
+func walkUp(cfg *config.Config, from string, steps int, choose func(parent string, children []string) (string, error)) (string, error) {
+	current := from
+	for moved := 0; steps < 0 || moved < steps; moved++ {
+		children := childrenOf(cfg, current)
+		if len(children) == 0 {
+			if moved == 0 {
+				return "", fmt.Errorf("'%s' is already at the top of its stack", from)
+			}
+			break
+		}
+
+		next := children[0]
+		if len(children) > 1 {
+			var err error
+			next, err = choose(current, children)
+			if err != nil {
+				return "", err
+			}
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// walkDown follows parent links from a branch for the given number of steps, stopping at trunk
+func walkDown(cfg *config.Config, from string, steps int) (string, error) {
+	if from == cfg.TrunkBranch {
+		return "", fmt.Errorf("already on trunk branch (%s)", cfg.TrunkBranch)
+	}
+
+	current := from
+	for moved := 0; moved < steps; moved++ {
+		branch, managed := cfg.ManagedBranches[current]
+		if !managed {
+			break
+		}
+		current = branch.Parent
+	}
+	return current, nil
+}
+
+// walkToBottom follows parent links down to the branch whose parent is not managed
+func walkToBottom(cfg *config.Config, from string) (string, error) {
+	if from == cfg.TrunkBranch {
+		return "", fmt.Errorf("already on trunk branch (%s)", cfg.TrunkBranch)
+	}
+
+	current := from
+	for range cfg.ManagedBranches {
+		parent := cfg.ManagedBranches[current].Parent
+		if _, managed := cfg.ManagedBranches[parent]; !managed {
+			return current, nil
+		}
+		current = parent
+	}
+	return "", fmt.Errorf("managed branches contain a parent cycle")
+}
+
+// chooseChild prompts the user to pick one of several children of a branch
+func chooseChild(parent string, children []string) (string, error) {
+	return promptSelect(fmt.Sprintf("'%s' has multiple children:", parent), children)
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"testing"
+)
+
+func TestWalkUp(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+		"d": "b",
+	})
+
+	choices := 0
+	choose := func(parent string, children []string) (string, error) {
+		choices++
+		if parent != "b" || len(children) != 2 {
+			t.Errorf("Unexpected choice between %v under %s", children, parent)
+		}
+		return "d", nil
+	}
+
+	if got, err := walkUp(cfg, "main", 1, choose); err != nil || got != "a" {
+		t.Errorf("walkUp(main, 1) = %q, %v; want \"a\"", got, err)
+	}
+	if got, err := walkUp(cfg, "a", 5, choose); err != nil || got != "d" {
+		t.Errorf("walkUp(a, 5) = %q, %v; want \"d\"", got, err)
+	}
+	if got, err := walkUp(cfg, "a", -1, choose); err != nil || got != "d" {
+		t.Errorf("walkUp(a, top) = %q, %v; want \"d\"", got, err)
+	}
+	if choices != 2 {
+		t.Errorf("Expected to be prompted twice, got %d", choices)
+	}
+	if _, err := walkUp(cfg, "c", 1, choose); err == nil {
+		t.Error("Expected an error when already at the top")
+	}
+}
+
+func TestWalkDown(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+	})
+
+	if got, err := walkDown(cfg, "c", 1); err != nil || got != "b" {
+		t.Errorf("walkDown(c, 1) = %q, %v; want \"b\"", got, err)
+	}
+	if got, err := walkDown(cfg, "c", 10); err != nil || got != "main" {
+		t.Errorf("walkDown(c, 10) = %q, %v; want \"main\"", got, err)
+	}
+	if _, err := walkDown(cfg, "main", 1); err == nil {
+		t.Error("Expected an error when already on trunk")
+	}
+	if got, err := walkToBottom(cfg, "c"); err != nil || got != "a" {
+		t.Errorf("walkToBottom(c) = %q, %v; want \"a\"", got, err)
+	}
+}
+
+func TestParseSteps(t *testing.T) {
+	if steps, err := parseSteps(nil); err != nil || steps != 1 {
+		t.Errorf("parseSteps(nil) = %d, %v; want 1", steps, err)
+	}
+	if steps, err := parseSteps([]string{"3"}); err != nil || steps != 3 {
+		t.Errorf("parseSteps(3) = %d, %v; want 3", steps, err)
+	}
+	if _, err := parseSteps([]string{"0"}); err == nil {
+		t.Error("Expected an error for zero steps")
+	}
+}
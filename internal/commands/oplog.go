@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var oplogCmd = &cobra.Command{
+	Use:   "oplog",
+	Short: "Show the log of gt operations",
+	Long:  `Show the log of mutating gt operations, newest first, with the branches each one changed. Any entry can be reverted with 'gt undo <id>'.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := config.LoadOpLog()
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println("No operations recorded yet")
+			return nil
+		}
+
+		for i := len(entries) - 1; i >= 0; i-- {
+			entry := entries[i]
+			fmt.Printf("#%d  %s  gt %s\n", entry.ID, entry.Timestamp.Local().Format("2006-01-02 15:04:05"), entry.Command)
+			for _, change := range entry.Branches {
+				fmt.Printf("    %s: %s -> %s\n", change.Name, shortSHA(change.Before), shortSHA(change.After))
+			}
+		}
+		return nil
+	},
+}
+
+// repoSnapshot captures the state that a gt command can change
+type repoSnapshot struct {
+	head     string
+	branches map[string]string
+	cfg      *config.Config
+}
+
+// takeSnapshot records the current branch, every local branch tip and the config
+func takeSnapshot(g git.Git) (*repoSnapshot, error) {
+	head, err := getCurrentBranch(g)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := listBranchSHAs(g)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return &repoSnapshot{head: head, branches: branches, cfg: cfg}, nil
+}
+
+// recordOperation wraps a mutating command so that its effect on branches and
+// config is appended to the operation log, even if the command fails part way.
+// The workspace lock is held for the whole command so that concurrent gt
+// processes cannot interleave their load-modify-save of the config.
+func recordOperation(run gitRunFunc) func(cmd *cobra.Command, args []string) error {
+	return withGit(func(g git.Git, cmd *cobra.Command, args []string) error {
+		unlock, err := config.Lock()
+		if err != nil {
+			return fmt.Errorf("failed to lock gt state: %w", err)
+		}
+		defer unlock()
+
+		before, err := takeSnapshot(g)
+		if err != nil {
+			// Without a snapshot there is nothing to record, but the command may still work
+			return run(g, cmd, args)
+		}
+
+		runErr := run(g, cmd, args)
+
+		command := strings.TrimSpace(cmd.Name() + " " + strings.Join(args, " "))
+		if err := logOperation(g, command, before); err != nil {
+			fmt.Printf("Warning: Failed to record operation: %v\n", err)
+		}
+		return runErr
+	})
+}
+
+// logOperation compares the repository against a snapshot taken before a
+// command and appends the changes to the operation log, if there are any
+func logOperation(g git.Git, command string, before *repoSnapshot) error {
+	after, err := takeSnapshot(g)
+	if err != nil {
+		return err
+	}
+
+	changes := diffBranchSHAs(before.branches, after.branches)
+	configChanged, err := configsDiffer(before.cfg, after.cfg)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 && !configChanged && before.head == after.head {
+		return nil
+	}
+
+	return config.AppendOpLog(&config.OpLogEntry{
+		Command:      command,
+		Timestamp:    time.Now().UTC(),
+		Branches:     changes,
+		HeadBefore:   before.head,
+		HeadAfter:    after.head,
+		ConfigBefore: before.cfg,
+		ConfigAfter:  after.cfg,
+	})
+}
+
+// diffBranchSHAs returns the branches that were created, moved or deleted
+// between two sets of branch tips, sorted by name
+func diffBranchSHAs(before, after map[string]string) []config.BranchChange {
+	changes := []config.BranchChange{}
+	for name, sha := range before {
+		if after[name] != sha {
+			changes = append(changes, config.BranchChange{Name: name, Before: sha, After: after[name]})
+		}
+	}
+	for name, sha := range after {
+		if _, existed := before[name]; !existed {
+			changes = append(changes, config.BranchChange{Name: name, After: sha})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// configsDiffer reports whether two configurations would be saved differently
+func configsDiffer(a, b *config.Config) (bool, error) {
+	dataA, err := json.Marshal(a)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	dataB, err := json.Marshal(b)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return string(dataA) != string(dataB), nil
+}
+
+// shortSHA abbreviates a commit SHA for display, showing missing branches as "(none)"
+func shortSHA(sha string) string {
+	if sha == "" {
+		return "(none)"
+	}
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/config"
+)
+
+func TestDiffBranchSHAs(t *testing.T) {
+	before := map[string]string{
+		"main":    "aaa",
+		"moved":   "bbb",
+		"deleted": "ccc",
+	}
+	after := map[string]string{
+		"main":    "aaa",
+		"moved":   "ddd",
+		"created": "eee",
+	}
+
+	changes := diffBranchSHAs(before, after)
+	expected := []config.BranchChange{
+		{Name: "created", Before: "", After: "eee"},
+		{Name: "deleted", Before: "ccc", After: ""},
+		{Name: "moved", Before: "bbb", After: "ddd"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("diffBranchSHAs() = %+v; want %+v", changes, expected)
+	}
+}
+
+func TestShortSHA(t *testing.T) {
+	if got := shortSHA(""); got != "(none)" {
+		t.Errorf("shortSHA(\"\") = %q; want \"(none)\"", got)
+	}
+	if got := shortSHA("0123456789abcdef"); got != "0123456" {
+		t.Errorf("shortSHA() = %q; want \"0123456\"", got)
+	}
+}
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// errNoRawTerminal is returned when the terminal cannot be switched to raw mode
+var errNoRawTerminal = errors.New("raw terminal mode is not available")
+
+// pickerItem is an entry in the interactive branch picker
+type pickerItem struct {
+	Name  string
+	Label string
+}
+
+// picker holds the state of the interactive selector: the full list of items,
+// the filter typed so far and the cursor position within the filtered list
+type picker struct {
+	items  []pickerItem
+	filter string
+	cursor int
+}
+
+// pickerKey is a decoded keypress
+type pickerKey int
+
+const (
+	keyNone pickerKey = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyCancel
+	keyBackspace
+	keyRune
+)
+
+// newPicker creates a picker with the cursor on the given item index
+func newPicker(items []pickerItem, cursor int) *picker {
+	p := &picker{items: items, cursor: cursor}
+	p.clampCursor()
+	return p
+}
+
+// visible returns the items that match the current filter, in display order
+func (p *picker) visible() []pickerItem {
+	if p.filter == "" {
+		return p.items
+	}
+	matches := []pickerItem{}
+	for _, item := range p.items {
+		if fuzzyMatch(p.filter, item.Name) {
+			matches = append(matches, item)
+		}
+	}
+	return matches
+}
+
+// selected returns the item under the cursor, if any
+func (p *picker) selected() (pickerItem, bool) {
+	visible := p.visible()
+	if len(visible) == 0 {
+		return pickerItem{}, false
+	}
+	return visible[p.cursor], true
+}
+
+// handleKey updates the picker for a keypress and reports whether selection is finished
+func (p *picker) handleKey(key pickerKey, r rune) (done bool, cancelled bool) {
+	switch key {
+	case keyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case keyDown:
+		p.cursor++
+	case keyEnter:
+		_, ok := p.selected()
+		return ok, false
+	case keyCancel:
+		return true, true
+	case keyBackspace:
+		if p.filter != "" {
+			runes := []rune(p.filter)
+			p.filter = string(runes[:len(runes)-1])
+			p.cursor = len(p.visible()) - 1
+		}
+	case keyRune:
+		p.filter += string(r)
+		// Keep the best (bottom-most) match selected as the filter narrows
+		p.cursor = len(p.visible()) - 1
+	}
+	p.clampCursor()
+	return false, false
+}
+
+func (p *picker) clampCursor() {
+	count := len(p.visible())
+	if p.cursor >= count {
+		p.cursor = count - 1
+	}
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+}
+
+// render returns the lines to draw for the current state
+func (p *picker) render() []string {
+	lines := []string{}
+	for i, item := range p.visible() {
+		prefix := "  "
+		if i == p.cursor {
+			prefix = "❯ "
+		}
+		lines = append(lines, prefix+item.Label)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "  (no matching branches)")
+	}
+	lines = append(lines, "Filter: "+p.filter)
+	return lines
+}
+
+// fuzzyMatch reports whether the characters of pattern appear in s in order, ignoring case
+func fuzzyMatch(pattern, s string) bool {
+	remaining := []rune(strings.ToLower(pattern))
+	for _, c := range strings.ToLower(s) {
+		if len(remaining) == 0 {
+			break
+		}
+		if c == remaining[0] {
+			remaining = remaining[1:]
+		}
+	}
+	return len(remaining) == 0
+}
+
+// decodeKey turns raw terminal input into a keypress
+func decodeKey(input []byte) (pickerKey, rune) {
+	switch {
+	case len(input) == 0:
+		return keyNone, 0
+	case len(input) >= 3 && input[0] == 0x1b && input[1] == '[' && input[2] == 'A':
+		return keyUp, 0
+	case len(input) >= 3 && input[0] == 0x1b && input[1] == '[' && input[2] == 'B':
+		return keyDown, 0
+	case input[0] == 0x10: // Ctrl-P
+		return keyUp, 0
+	case input[0] == 0x0e: // Ctrl-N
+		return keyDown, 0
+	case input[0] == '\r' || input[0] == '\n':
+		return keyEnter, 0
+	case input[0] == 0x03 || (len(input) == 1 && input[0] == 0x1b): // Ctrl-C or Esc
+		return keyCancel, 0
+	case input[0] == 0x7f || input[0] == 0x08:
+		return keyBackspace, 0
+	}
+
+	r := []rune(string(input))[0]
+	if unicode.IsPrint(r) {
+		return keyRune, r
+	}
+	return keyNone, 0
+}
+
+// splitKeys splits a read from the terminal into individual keypresses, so that
+// typed-ahead or pasted text is handled one character at a time and arrow keys
+// pressed in quick succession are not read as one
+func splitKeys(input []byte) [][]byte {
+	keys := [][]byte{}
+	for len(input) > 0 {
+		n := keyLength(input)
+		keys = append(keys, input[:n])
+		input = input[n:]
+	}
+	return keys
+}
+
+// keyLength returns the length of the keypress at the start of input: a whole
+// escape sequence, a lone Esc or a single character
+func keyLength(input []byte) int {
+	if input[0] != 0x1b {
+		_, size := utf8.DecodeRune(input)
+		return size
+	}
+	if len(input) < 3 || (input[1] != '[' && input[1] != 'O') {
+		return 1
+	}
+	if input[1] == 'O' {
+		return 3
+	}
+	// A CSI sequence ends at its first byte in the range '@' to '~'
+	for i := 2; i < len(input); i++ {
+		if input[i] >= 0x40 && input[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return len(input)
+}
+
+// isInteractiveTerminal reports whether stdin and stdout are both terminals
+func isInteractiveTerminal() bool {
+	for _, file := range []*os.File{os.Stdin, os.Stdout} {
+		info, err := file.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// runPicker shows the interactive selector in raw terminal mode and returns the
+// chosen item name, or an empty string if the user cancelled
+func runPicker(title string, p *picker) (string, error) {
+	restore, err := makeRawTerminal()
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	fmt.Print(title + "\r\n")
+	drawn := 0
+	buf := make([]byte, 16)
+	for {
+		// Move back over the previous frame and clear it before drawing the next
+		if drawn > 0 {
+			fmt.Printf("\x1b[%dA\r\x1b[J", drawn)
+		}
+		lines := p.render()
+		fmt.Print(strings.Join(lines, "\r\n"))
+		drawn = len(lines) - 1
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+
+		done, cancelled := false, false
+		for _, input := range splitKeys(buf[:n]) {
+			key, r := decodeKey(input)
+			if done, cancelled = p.handleKey(key, r); done {
+				break
+			}
+		}
+		if !done {
+			continue
+		}
+
+		fmt.Print("\r\n")
+		if cancelled {
+			return "", nil
+		}
+		item, _ := p.selected()
+		return item.Name, nil
+	}
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		s        string
+		expected bool
+	}{
+		{"", "anything", true},
+		{"fbp", "fix-bug-in-parser", true},
+		{"FIX", "fix-bug", true},
+		{"pf", "fix-bug-in-parser", false},
+		{"xyz", "main", false},
+	}
+
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.pattern, tt.s); got != tt.expected {
+			t.Errorf("fuzzyMatch(%q, %q) = %v; want %v", tt.pattern, tt.s, got, tt.expected)
+		}
+	}
+}
+
+func TestDecodeKey(t *testing.T) {
+	tests := []struct {
+		input    []byte
+		key      pickerKey
+		expected rune
+	}{
+		{[]byte{0x1b, '[', 'A'}, keyUp, 0},
+		{[]byte{0x1b, '[', 'B'}, keyDown, 0},
+		{[]byte{'\r'}, keyEnter, 0},
+		{[]byte{0x03}, keyCancel, 0},
+		{[]byte{0x1b}, keyCancel, 0},
+		{[]byte{0x7f}, keyBackspace, 0},
+		{[]byte{'x'}, keyRune, 'x'},
+	}
+
+	for _, tt := range tests {
+		key, r := decodeKey(tt.input)
+		if key != tt.key || r != tt.expected {
+			t.Errorf("decodeKey(%v) = %v, %q; want %v, %q", tt.input, key, r, tt.key, tt.expected)
+		}
+	}
+}
+
+func TestSplitKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"\x1b[A\x1b[A\x1b[B", []string{"\x1b[A", "\x1b[A", "\x1b[B"}},
+		{"ab\x1b[B\r", []string{"a", "b", "\x1b[B", "\r"}},
+		{"\x1b[1;5A\x1b", []string{"\x1b[1;5A", "\x1b"}},
+		{"é\x1b[A", []string{"é", "\x1b[A"}},
+	}
+
+	for _, tt := range tests {
+		keys := []string{}
+		for _, key := range splitKeys([]byte(tt.input)) {
+			keys = append(keys, string(key))
+		}
+		if !reflect.DeepEqual(keys, tt.expected) {
+			t.Errorf("splitKeys(%q) = %q; want %q", tt.input, keys, tt.expected)
+		}
+	}
+}
+
+func TestPickerFilterAndSelect(t *testing.T) {
+	items := []pickerItem{
+		{Name: "old-feature", Label: "old-feature"},
+		{Name: "fix-parser", Label: "fix-parser"},
+		{Name: "add-feature", Label: "add-feature"},
+		{Name: "main", Label: "main"},
+	}
+	p := newPicker(items, 2)
+
+	if item, _ := p.selected(); item.Name != "add-feature" {
+		t.Errorf("Expected initial selection 'add-feature', got '%s'", item.Name)
+	}
+
+	p.handleKey(keyUp, 0)
+	if item, _ := p.selected(); item.Name != "fix-parser" {
+		t.Errorf("Expected 'fix-parser' after moving up, got '%s'", item.Name)
+	}
+
+	for _, r := range "feat" {
+		p.handleKey(keyRune, r)
+	}
+	if len(p.visible()) != 2 {
+		t.Fatalf("Expected 2 matches for 'feat', got %d", len(p.visible()))
+	}
+	if item, _ := p.selected(); item.Name != "add-feature" {
+		t.Errorf("Expected bottom-most match 'add-feature', got '%s'", item.Name)
+	}
+
+	p.handleKey(keyDown, 0)
+	if item, _ := p.selected(); item.Name != "add-feature" {
+		t.Errorf("Expected cursor to stay on last match, got '%s'", item.Name)
+	}
+
+	done, cancelled := p.handleKey(keyEnter, 0)
+	if !done || cancelled {
+		t.Errorf("Expected enter to finish selection, got done=%v cancelled=%v", done, cancelled)
+	}
+
+	for _, r := range "zzz" {
+		p.handleKey(keyRune, r)
+	}
+	if done, _ := p.handleKey(keyEnter, 0); done {
+		t.Error("Expected enter to be ignored when nothing matches")
+	}
+}
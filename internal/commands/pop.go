@@ -4,58 +4,96 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
 )
 
 var popCmd = &cobra.Command{
 	Use:   "pop",
 	Short: "Undo the current branch and commit",
-	Long:  `Undo the current branch and commit, returning the files from the commit/branch to an uncommitted state. This effectively undoes the 'create' command. Will not run on trunk branch.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		// Check if we're on trunk branch and load config
-		onTrunk, cfg, err := isOnTrunkBranch()
+	Long:  `Undo the current branch and commit, returning the files from the commit/branch to an uncommitted state. This effectively undoes the 'create' command. Branches stacked on the popped branch are moved onto its parent and restacked. Will not run on trunk branch.`,
+	RunE:  recordOperation(runPop),
+}
+
+func runPop(g git.Git, cmd *cobra.Command, args []string) error {
+	// Check if we're on trunk branch and load config
+	onTrunk, cfg, err := isOnTrunkBranch(g)
+	if err != nil {
+		return err
+	}
+	if onTrunk {
+		return fmt.Errorf("pop command cannot be run on trunk branch (%s)", cfg.TrunkBranch)
+	}
+	if err := ensureNoOperationInProgress(); err != nil {
+		return err
+	}
+
+	// Get current branch name
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+
+	// Get parent branch from config (if managed), otherwise default to trunk
+	parentBranch := cfg.TrunkBranch
+	if branchInfo, exists := cfg.ManagedBranches[currentBranch]; exists {
+		parentBranch = branchInfo.Parent
+	}
+
+	// Move any branches stacked on top onto the parent and restack them first,
+	// while the working tree is still clean enough to rebase them
+	if len(childrenOf(cfg, currentBranch)) > 0 {
+		clean, err := isWorkingTreeClean(g)
 		if err != nil {
 			return err
 		}
-		if onTrunk {
-			return fmt.Errorf("pop command cannot be run on trunk branch (%s)", cfg.TrunkBranch)
+		if !clean {
+			return fmt.Errorf("branch '%s' has branches stacked on it; commit or stash your changes before popping it", currentBranch)
 		}
 
-		// Get current branch name
-		currentBranch, err := getCurrentBranch()
+		before, err := takeSnapshot(g)
 		if err != nil {
 			return err
 		}
-
-		// Get parent branch from config (if managed), otherwise default to trunk
-		parentBranch := cfg.TrunkBranch
-		if branchInfo, exists := cfg.ManagedBranches[currentBranch]; exists {
-			parentBranch = branchInfo.Parent
-		}
-
-		// Reset the last commit (keeping changes in working directory)
-		if err := resetLastCommit(); err != nil {
+		descendants, err := reparentChildren(g, cfg, currentBranch, parentBranch)
+		if err != nil {
 			return err
 		}
-
-		// Checkout to parent branch
-		if err := checkoutBranch(parentBranch); err != nil {
-			return err
+		// Save the new parents first; 'gt continue' reloads the config from disk
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
 		}
-
-		// Delete the branch
-		if err := deleteBranch(currentBranch); err != nil {
+		if err := startRestackFrom(g, cfg, before, "pop", descendants, currentBranch); err != nil {
+			if op, _ := config.LoadOperation(); op != nil {
+				return fmt.Errorf("%w\nOnce it is complete, run 'gt pop' again to pop '%s'", err, currentBranch)
+			}
 			return err
 		}
+	}
 
-		// Remove branch from managed branches if it exists
-		if _, exists := cfg.ManagedBranches[currentBranch]; exists {
-			delete(cfg.ManagedBranches, currentBranch)
-			if err := cfg.Save(); err != nil {
-				return fmt.Errorf("failed to save config: %w", err)
-			}
+	// Reset the last commit (keeping changes in working directory)
+	if err := resetLastCommit(g); err != nil {
+		return err
+	}
+
+	// Checkout to parent branch
+	if err := checkoutBranch(g, parentBranch); err != nil {
+		return err
+	}
+
+	// Delete the branch
+	if err := deleteBranch(g, currentBranch); err != nil {
+		return err
+	}
+
+	// Remove branch from managed branches if it exists
+	if _, exists := cfg.ManagedBranches[currentBranch]; exists {
+		delete(cfg.ManagedBranches, currentBranch)
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
 		}
+	}
 
-		fmt.Printf("Popped branch '%s' and returned to '%s' with uncommitted changes\n", currentBranch, parentBranch)
-		return nil
-	},
+	fmt.Printf("Popped branch '%s' and returned to '%s' with uncommitted changes\n", currentBranch, parentBranch)
+	return nil
 }
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/config"
+)
+
+func TestPopSavesReparentedChildrenBeforeConflict(t *testing.T) {
+	useScratchRepo(t)
+	rebaseDir := t.TempDir()
+
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+	})
+	setParentSHA(cfg, "c", "btip")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	g := newConflictFake(rebaseDir)
+	g.Fail("rebase --onto atip btip c")
+
+	if err := runPop(g, nil, nil); err == nil {
+		t.Fatal("Expected runPop to stop on the conflict")
+	}
+	if g.Called("reset --soft HEAD~1") {
+		t.Error("Expected 'b' not to be popped while the restack is stopped")
+	}
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if c := saved.ManagedBranches["c"]; c.Parent != "a" || c.ParentSHA != "btip" {
+		t.Errorf("Expected 'c' saved on 'a' from 'btip' while the rebase is stopped, got %+v", c)
+	}
+
+	// Resolve the conflict and continue
+	if err := os.Remove(rebaseDir); err != nil {
+		t.Fatalf("Failed to remove rebase dir: %v", err)
+	}
+	if err := runContinue(g, nil, nil); err != nil {
+		t.Fatalf("runContinue returned error: %v", err)
+	}
+
+	saved, err = config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if c := saved.ManagedBranches["c"]; c.Parent != "a" || c.ParentSHA != "atip" {
+		t.Errorf("Expected 'c' on 'a' from 'atip' after continuing, got %+v", c)
+	}
+}
+
+func TestPopRefusesWhileOperationInProgress(t *testing.T) {
+	useScratchRepo(t)
+
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+	})
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	op := &config.Operation{Command: "modify", OriginalBranch: "a", OriginalConfig: cfg}
+	if err := op.Save(); err != nil {
+		t.Fatalf("Failed to save operation: %v", err)
+	}
+
+	if err := runPop(newConflictFake(t.TempDir()), nil, nil); err == nil {
+		t.Fatal("Expected runPop to refuse while another operation is in progress")
+	}
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if c := saved.ManagedBranches["c"]; c.Parent != "b" {
+		t.Errorf("Expected 'c' to stay on 'b', got %+v", c)
+	}
+}
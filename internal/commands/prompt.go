@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+// stdinReader is shared by all prompts so that buffered input is not lost between them
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// promptLine prints a prompt and returns the trimmed line the user typed
+func promptLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	response, err := stdinReader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(response), nil
+}
+
+// promptConfirm asks a yes/no question and reports whether the user answered yes
+func promptConfirm(question string) (bool, error) {
+	response, err := promptLine(question + " (y/n): ")
+	if err != nil {
+		return false, err
+	}
+	response = strings.ToLower(response)
+	return response == "y" || response == "yes", nil
+}
+
+// promptSelect lists the options as a numbered menu and returns the one the user picks
+func promptSelect(title string, options []string) (string, error) {
+	index, err := promptSelectIndex(title, options)
+	if err != nil {
+		return "", err
+	}
+	return options[index], nil
+}
+
+// promptSelectIndex lists the options as a numbered menu and returns the index of the one the user picks
+func promptSelectIndex(title string, options []string) (int, error) {
+	fmt.Println(title)
+	for i, option := range options {
+		fmt.Printf("  %d) %s\n", i+1, option)
+	}
+
+	for {
+		response, err := promptLine(fmt.Sprintf("Select 1-%d: ", len(options)))
+		if err != nil {
+			return 0, err
+		}
+		choice, err := strconv.Atoi(response)
+		if err == nil && choice >= 1 && choice <= len(options) {
+			return choice - 1, nil
+		}
+		fmt.Printf("Invalid selection '%s'\n", response)
+	}
+}
+
+// editText writes text to a temporary file, opens it in the user's git editor
+// and returns the edited contents
+func editText(g git.Git, pattern, text string) (string, error) {
+	editor, err := g.Run("var", "GIT_EDITOR")
+	if err != nil {
+		return "", fmt.Errorf("failed to find an editor: %w", err)
+	}
+
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	path := file.Name()
+	defer os.Remove(path)
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	// Run the editor through the shell, as git does, so that editors configured
+	// with arguments work
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor '%s' failed: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited file: %w", err)
+	}
+	return string(data), nil
+}
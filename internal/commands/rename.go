@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+	"github.com/th1nkful/cli-gt/internal/github"
+)
+
+var renameRemote bool
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <new-name>",
+	Short: "Rename the current branch and update the stack",
+	Long:  `Rename the current branch, moving its gt metadata to the new name and pointing the branches stacked on it at the new name. With --remote the branch is also renamed on GitHub, which keeps its pull request, and the pull requests of branches stacked on it are retargeted to the new name; this needs GITHUB_TOKEN (or GH_TOKEN) like 'gt submit'. Will not run on trunk branch.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  recordOperation(runRename),
+}
+
+func runRename(g git.Git, cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	oldName, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+	newName := args[0]
+	if oldName == cfg.TrunkBranch {
+		return fmt.Errorf("Error: gt rename cannot be run on %s", cfg.TrunkBranch)
+	}
+	if _, managed := cfg.ManagedBranches[oldName]; !managed {
+		return fmt.Errorf("branch '%s' is not tracked by gt", oldName)
+	}
+	if err := ensureNoOperationInProgress(); err != nil {
+		return err
+	}
+	if newName == oldName {
+		fmt.Printf("Branch is already named '%s'\n", oldName)
+		return nil
+	}
+	if _, err := g.Run("check-ref-format", "--branch", newName); err != nil {
+		return fmt.Errorf("'%s' is not a valid branch name", newName)
+	}
+
+	localExists, remoteExists, err := branchExists(g, oldName)
+	if err != nil && renameRemote {
+		return err
+	}
+	if newLocal, _, _ := branchExists(g, newName); newLocal {
+		return fmt.Errorf("branch '%s' already exists locally", newName)
+	}
+	if !localExists {
+		return fmt.Errorf("branch '%s' does not exist locally", oldName)
+	}
+
+	// Rename on GitHub first, so a failure there leaves everything as it was
+	var client *github.Client
+	if renameRemote {
+		if !remoteExists {
+			fmt.Printf("Branch '%s' is not on origin; renaming it locally only\n", oldName)
+		} else {
+			if client, err = newGitHubClient(g); err != nil {
+				return err
+			}
+			if _, err := client.RenameBranch(oldName, newName); err != nil {
+				return fmt.Errorf("failed to rename '%s' on GitHub: %w", oldName, err)
+			}
+			fmt.Printf("Renamed '%s' to '%s' on GitHub\n", oldName, newName)
+		}
+	}
+
+	if _, err := g.Run("branch", "-m", oldName, newName); err != nil {
+		return fmt.Errorf("failed to rename branch '%s': %w", oldName, err)
+	}
+	renameBranchConfig(cfg, oldName, newName)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf("Renamed branch '%s' to '%s'\n", oldName, newName)
+
+	if client == nil {
+		if remoteExists && !renameRemote {
+			fmt.Printf("The branch is still named '%s' on origin; use --remote to rename it there too\n", oldName)
+		}
+		return nil
+	}
+
+	if err := retargetChildPullRequests(client, cfg, newName); err != nil {
+		return err
+	}
+
+	// Follow the renamed branch on origin and move its shared metadata
+	if _, err := g.Run("fetch", "--prune", "origin"); err != nil {
+		return fmt.Errorf("failed to fetch from origin: %w", err)
+	}
+	if _, err := g.Run("branch", "--set-upstream-to=origin/"+newName, newName); err != nil {
+		return fmt.Errorf("failed to set upstream of '%s': %w", newName, err)
+	}
+	if cfg.Storage == config.StorageRefs {
+		for _, branchName := range append([]string{newName}, childrenOf(cfg, newName)...) {
+			if err := pushBranchMetadata(g, cfg, branchName); err != nil {
+				return err
+			}
+		}
+		if _, err := g.Run("push", "origin", "--delete", config.MetaRef(oldName)); err != nil {
+			fmt.Printf("Warning: Failed to delete metadata for '%s' on origin: %v\n", oldName, err)
+		}
+	}
+	return nil
+}
+
+// renameBranchConfig moves a managed branch's metadata to its new name and
+// points its children at it
+func renameBranchConfig(cfg *config.Config, oldName, newName string) {
+	branch := cfg.ManagedBranches[oldName]
+	delete(cfg.ManagedBranches, oldName)
+	branch.Name = newName
+	cfg.ManagedBranches[newName] = branch
+
+	for name, child := range cfg.ManagedBranches {
+		if child.Parent == oldName {
+			child.Parent = newName
+			cfg.ManagedBranches[name] = child
+		}
+	}
+}
+
+// retargetChildPullRequests makes sure the open pull requests of a branch's
+// children are based on its current name. GitHub usually retargets them
+// itself when a branch is renamed; this catches any it did not.
+func retargetChildPullRequests(client *github.Client, cfg *config.Config, branchName string) error {
+	for _, child := range childrenOf(cfg, branchName) {
+		number := cfg.ManagedBranches[child].PRNumber
+		if number == 0 {
+			continue
+		}
+		pull, err := client.GetPullRequest(number)
+		if err != nil {
+			return fmt.Errorf("failed to get PR #%d: %w", number, err)
+		}
+		if pull.State != "open" || pull.Base.Ref == branchName {
+			continue
+		}
+		if _, err := client.UpdatePullRequestBase(number, branchName); err != nil {
+			return fmt.Errorf("failed to update base of PR #%d: %w", number, err)
+		}
+		fmt.Printf("Retargeted PR #%d for '%s' to '%s'\n", number, child, branchName)
+	}
+	return nil
+}
+
+func init() {
+	renameCmd.Flags().BoolVar(&renameRemote, "remote", false, "Also rename the branch on GitHub and retarget pull requests")
+}
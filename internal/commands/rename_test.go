@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/github"
+)
+
+func TestRenameBranchConfig(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "a",
+		"d": "b",
+	})
+	a := cfg.ManagedBranches["a"]
+	a.PRNumber = 3
+	cfg.ManagedBranches["a"] = a
+
+	renameBranchConfig(cfg, "a", "renamed")
+
+	if _, ok := cfg.ManagedBranches["a"]; ok {
+		t.Error("Expected 'a' to be removed from managed branches")
+	}
+	if renamed := cfg.ManagedBranches["renamed"]; renamed.Name != "renamed" || renamed.Parent != "main" || renamed.PRNumber != 3 {
+		t.Errorf("Expected 'renamed' to keep the metadata of 'a', got %+v", renamed)
+	}
+	for _, child := range []string{"b", "c"} {
+		if parent := cfg.ManagedBranches[child].Parent; parent != "renamed" {
+			t.Errorf("Expected '%s' on 'renamed', got '%s'", child, parent)
+		}
+	}
+	if parent := cfg.ManagedBranches["d"].Parent; parent != "b" {
+		t.Errorf("Expected 'd' to stay on 'b', got '%s'", parent)
+	}
+}
+
+func TestRetargetChildPullRequests(t *testing.T) {
+	server := github.NewFakeServer("owner", "repo", "token")
+	defer server.Close()
+	client := github.NewClient(server.URL, "token", "owner", "repo")
+
+	pull, err := client.CreatePullRequest("b", "a", "Add b", "", false)
+	if err != nil {
+		t.Fatalf("Failed to create pull request: %v", err)
+	}
+
+	cfg := newStackConfig(map[string]string{
+		"renamed": "main",
+		"b":       "renamed",
+	})
+	b := cfg.ManagedBranches["b"]
+	b.PRNumber = pull.Number
+	cfg.ManagedBranches["b"] = b
+
+	if err := retargetChildPullRequests(client, cfg, "renamed"); err != nil {
+		t.Fatalf("retargetChildPullRequests returned error: %v", err)
+	}
+	if stored, _ := server.PullRequest(pull.Number); stored.Base.Ref != "renamed" {
+		t.Errorf("Expected PR base 'renamed', got '%s'", stored.Base.Ref)
+	}
+}
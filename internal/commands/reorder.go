@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var reorderCmd = &cobra.Command{
+	Use:   "reorder",
+	Short: "Reorder the branches of the current stack in an editor",
+	Long: `Open an editor listing the branches of the current stack, from the one closest to trunk up to the top of the stack, like 'git rebase -i'.
+Reorder the lines, save and quit, and each branch is stacked on the one listed above it and its commits are replayed in the new order.
+The stack must be linear and the working tree clean. If a rebase stops on a conflict, resolve it and run 'gt continue', or run 'gt abort' to roll back.`,
+	Args: cobra.NoArgs,
+	RunE: recordOperation(runReorder),
+}
+
+func runReorder(g git.Git, cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+	if _, managed := cfg.ManagedBranches[currentBranch]; !managed {
+		return fmt.Errorf("branch '%s' is not tracked by gt", currentBranch)
+	}
+	if err := ensureNoOperationInProgress(); err != nil {
+		return err
+	}
+	clean, err := isWorkingTreeClean(g)
+	if err != nil {
+		return err
+	}
+	if !clean {
+		return fmt.Errorf("commit or stash your changes before reordering a stack")
+	}
+
+	stack, err := linearStack(cfg, currentBranch)
+	if err != nil {
+		return err
+	}
+	if len(stack) < 2 {
+		fmt.Println("Nothing to reorder: the stack has a single branch")
+		return nil
+	}
+
+	edited, err := editText(g, "gt-reorder-*.txt", reorderTemplate(cfg, stack))
+	if err != nil {
+		return err
+	}
+	order, err := parseReorder(edited, stack)
+	if err != nil {
+		return err
+	}
+	if strings.Join(order, " ") == strings.Join(stack, " ") {
+		fmt.Println("Stack order unchanged")
+		return nil
+	}
+
+	before, err := takeSnapshot(g)
+	if err != nil {
+		return err
+	}
+	if err := applyReorder(g, cfg, cfg.ManagedBranches[stack[0]].Parent, order); err != nil {
+		return err
+	}
+	// Save the new order first; 'gt continue' reloads the config from disk
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if err := startRestackFrom(g, cfg, before, "reorder", order, currentBranch); err != nil {
+		return err
+	}
+
+	fmt.Printf("Reordered stack: %s\n", strings.Join(order, " -> "))
+	return nil
+}
+
+// linearStack returns the branches of the stack containing the given branch,
+// parent-first, or an error if any branch in it has more than one child
+func linearStack(cfg *config.Config, branchName string) ([]string, error) {
+	stack := currentStack(cfg, branchName)
+	for _, name := range stack {
+		if children := childrenOf(cfg, name); len(children) > 1 {
+			return nil, fmt.Errorf("cannot reorder: branch '%s' has more than one child (%s); only linear stacks can be reordered", name, strings.Join(children, ", "))
+		}
+	}
+	return stack, nil
+}
+
+// reorderTemplate lists the stack's branches, bottom first, for the user to rearrange
+func reorderTemplate(cfg *config.Config, stack []string) string {
+	var out strings.Builder
+	for _, name := range stack {
+		fmt.Fprintf(&out, "%s\n", name)
+	}
+	fmt.Fprintf(&out, "\n# Reorder the branches of this stack. The first line is stacked on %s\n", cfg.ManagedBranches[stack[0]].Parent)
+	out.WriteString("# and every other line on the one above it.\n")
+	out.WriteString("# Every branch must stay listed exactly once. Lines starting with '#' are ignored;\n")
+	out.WriteString("# leave the order as it is to cancel.\n")
+	return out.String()
+}
+
+// parseReorder reads the edited branch list, which must name every branch of
+// the stack exactly once
+func parseReorder(input string, stack []string) ([]string, error) {
+	remaining := map[string]bool{}
+	for _, name := range stack {
+		remaining[name] = true
+	}
+
+	order := []string{}
+	for _, line := range strings.Split(input, "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		if !remaining[name] {
+			if containsString(order, name) {
+				return nil, fmt.Errorf("branch '%s' is listed more than once", name)
+			}
+			return nil, fmt.Errorf("branch '%s' is not part of the stack", name)
+		}
+		delete(remaining, name)
+		order = append(order, name)
+	}
+
+	for _, name := range stack {
+		if remaining[name] {
+			return nil, fmt.Errorf("branch '%s' is missing from the new order; every branch must stay listed", name)
+		}
+	}
+	return order, nil
+}
+
+// applyReorder stacks the first branch of the new order on root and every
+// other branch on the one before it. Each branch's base is pinned before any
+// parent changes, so that restacking replays only the branch's own commits.
+func applyReorder(g git.Git, cfg *config.Config, root string, order []string) error {
+	bases := map[string]string{}
+	for _, name := range order {
+		base, err := branchBase(g, cfg, name)
+		if err != nil {
+			return fmt.Errorf("failed to find base of '%s': %w", name, err)
+		}
+		bases[name] = base
+	}
+
+	parent := root
+	for _, name := range order {
+		branch := cfg.ManagedBranches[name]
+		branch.Parent = parent
+		branch.ParentSHA = bases[name]
+		cfg.ManagedBranches[name] = branch
+		parent = name
+	}
+	return nil
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
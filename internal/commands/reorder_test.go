@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+func TestParseReorder(t *testing.T) {
+	stack := []string{"a", "b", "c"}
+
+	order, err := parseReorder("c\n  a\n# comment\n\nb\n", stack)
+	if err != nil {
+		t.Fatalf("parseReorder returned error: %v", err)
+	}
+	if !reflect.DeepEqual(order, []string{"c", "a", "b"}) {
+		t.Errorf("Expected order [c a b], got %v", order)
+	}
+
+	for _, input := range []string{"a\nb\n", "a\nb\nc\nb\n", "a\nb\nc\nd\n"} {
+		if _, err := parseReorder(input, stack); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
+
+func TestApplyReorderPinsBases(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+	})
+	setParentSHA(cfg, "a", "mainbase")
+	setParentSHA(cfg, "b", "atip")
+	setParentSHA(cfg, "c", "btip")
+
+	if err := applyReorder(git.NewFake(), cfg, "main", []string{"b", "a", "c"}); err != nil {
+		t.Fatalf("applyReorder returned error: %v", err)
+	}
+
+	expected := map[string][2]string{
+		"b": {"main", "atip"},
+		"a": {"b", "mainbase"},
+		"c": {"a", "btip"},
+	}
+	for name, want := range expected {
+		branch := cfg.ManagedBranches[name]
+		if branch.Parent != want[0] || branch.ParentSHA != want[1] {
+			t.Errorf("Expected '%s' on '%s' from '%s', got %+v", name, want[0], want[1], branch)
+		}
+	}
+}
+
+func TestLinearStackRejectsForks(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "a",
+	})
+
+	if _, err := linearStack(cfg, "b"); err == nil {
+		t.Error("Expected an error for a stack with a fork")
+	}
+
+	stack, err := linearStack(newStackConfig(map[string]string{"a": "main", "b": "a"}), "a")
+	if err != nil {
+		t.Fatalf("linearStack returned error: %v", err)
+	}
+	if !reflect.DeepEqual(stack, []string{"a", "b"}) {
+		t.Errorf("Expected stack [a b], got %v", stack)
+	}
+}
+
+func TestReorderSavesNewOrderBeforeConflict(t *testing.T) {
+	useScratchRepo(t)
+	rebaseDir := t.TempDir()
+
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+	})
+	setParentSHA(cfg, "a", "maintip")
+	setParentSHA(cfg, "b", "atip")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	g := newConflictFake(rebaseDir)
+	// The editor swaps the two branch lines
+	g.On("var GIT_EDITOR", `sed -i '1{h;d};2{G}'`)
+	g.Fail("rebase --onto maintip atip b")
+
+	if err := runReorder(g, nil, nil); err == nil {
+		t.Fatal("Expected runReorder to stop on the conflict")
+	}
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if b := saved.ManagedBranches["b"]; b.Parent != "main" {
+		t.Errorf("Expected 'b' saved on 'main' while the rebase is stopped, got %+v", b)
+	}
+	if a := saved.ManagedBranches["a"]; a.Parent != "b" || a.ParentSHA != "maintip" {
+		t.Errorf("Expected 'a' saved on 'b' from 'maintip', got %+v", a)
+	}
+}
+
+func TestReorderRefusesDirtyWorkingTree(t *testing.T) {
+	useScratchRepo(t)
+
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+	})
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	g := newConflictFake(t.TempDir())
+	g.On("status --porcelain --untracked-files=no", " M f")
+
+	if err := runReorder(g, nil, nil); err == nil {
+		t.Fatal("Expected runReorder to refuse a dirty working tree")
+	}
+	if g.Called("var GIT_EDITOR") {
+		t.Error("Expected the editor not to be opened")
+	}
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if a := saved.ManagedBranches["a"]; a.Parent != "main" {
+		t.Errorf("Expected the order to be left alone, got %+v", a)
+	}
+}
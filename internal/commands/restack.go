@@ -4,14 +4,232 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
 )
 
 var restackCmd = &cobra.Command{
 	Use:   "restack",
 	Short: "Restack all managed branches",
-	Long:  `Restack all managed branches to ensure they are up to date with their parent branch (assuming always trunk for now).`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("restack command - not yet implemented")
+	Long:  `Restack all managed branches so that each one is based on the current tip of its parent branch. Branches are processed parent-first, so an entire stack stays consistent after any lower branch changes. If a rebase stops on a conflict, resolve it and run 'gt continue', or run 'gt abort' to roll every branch back.`,
+	RunE:  recordOperation(runRestack),
+}
+
+func runRestack(g git.Git, cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Save the current branch to return to later
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+
+	order, err := topoOrder(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := startRestack(g, cfg, "restack", order, currentBranch); err != nil {
+		return err
+	}
+
+	fmt.Println("Restack complete!")
+	return nil
+}
+
+// startRestack begins a resumable operation that restacks the given branches
+// in order and then checks out returnTo. If a rebase stops on a conflict the
+// operation is persisted so that 'gt continue' or 'gt abort' can pick it up.
+func startRestack(g git.Git, cfg *config.Config, command string, branches []string, returnTo string) error {
+	current, err := listBranchSHAs(g)
+	if err != nil {
+		return err
+	}
+	return startRestackFrom(g, cfg, &repoSnapshot{branches: current, cfg: cfg.Clone()}, command, branches, returnTo)
+}
+
+// startRestackFrom is startRestack for commands that create, move or delete
+// branches or rearrange the stack in cfg before restacking. 'gt abort' restores
+// the config and every branch tip from the snapshot taken before those changes.
+func startRestackFrom(g git.Git, cfg *config.Config, before *repoSnapshot, command string, branches []string, returnTo string) error {
+	if err := ensureNoOperationInProgress(); err != nil {
+		return err
+	}
+
+	op := &config.Operation{
+		Command:        command,
+		OriginalBranch: returnTo,
+		Remaining:      branches,
+		OriginalSHAs:   make(map[string]string),
+		OriginalConfig: before.cfg,
+	}
+	if before.head != returnTo {
+		op.AbortBranch = before.head
+	}
+	for _, branchName := range branches {
+		if sha, ok := before.branches[branchName]; ok {
+			op.OriginalSHAs[branchName] = sha
+		}
+	}
+
+	// Branches the command already changed; an empty SHA marks one it created
+	current, err := listBranchSHAs(g)
+	if err != nil {
+		return err
+	}
+	for _, change := range diffBranchSHAs(before.branches, current) {
+		op.OriginalSHAs[change.Name] = change.Before
+	}
+
+	return resumeRestack(g, cfg, op)
+}
+
+// ensureNoOperationInProgress returns an error if a previous operation is waiting
+// to be continued or aborted
+func ensureNoOperationInProgress() error {
+	existing, err := config.LoadOperation()
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("a gt %s is already in progress; run 'gt continue' or 'gt abort' first", existing.Command)
+	}
+	return nil
+}
+
+// resumeRestack restacks the remaining branches of an operation, saving the
+// config after every branch so progress is kept if a later one fails
+func resumeRestack(g git.Git, cfg *config.Config, op *config.Operation) error {
+	for len(op.Remaining) > 0 {
+		branchName := op.Remaining[0]
+		op.Remaining = op.Remaining[1:]
+
+		restacked, err := restackBranch(g, cfg, branchName, op)
+		if err != nil {
+			// Only a conflict leaves the operation resumable; otherwise go back where we started
+			if op.Current != branchName {
+				if checkoutErr := checkoutBranch(g, op.OriginalBranch); checkoutErr != nil {
+					fmt.Printf("Warning: Failed to return to branch '%s': %v\n", op.OriginalBranch, checkoutErr)
+				}
+			}
+			return err
+		}
+		if !restacked {
+			continue
+		}
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+	}
+
+	if err := config.ClearOperation(); err != nil {
+		return err
+	}
+
+	return checkoutBranch(g, op.OriginalBranch)
+}
+
+// restackBranch rebases a managed branch onto the current tip of its parent,
+// replaying only the commits made after its recorded parent base.
+// Returns whether the branch was rebased. On a conflict the operation is
+// saved with the branch as its current step and an error is returned.
+func restackBranch(g git.Git, cfg *config.Config, branchName string, op *config.Operation) (bool, error) {
+	branch, ok := cfg.ManagedBranches[branchName]
+	if !ok {
+		return false, fmt.Errorf("branch '%s' is not managed by gt", branchName)
+	}
+
+	localExists, _, _ := branchExists(g, branchName)
+	if !localExists {
+		fmt.Printf("Skipping '%s': branch does not exist locally\n", branchName)
+		return false, nil
+	}
+
+	parentTip, err := revParse(g, branch.Parent)
+	if err != nil {
+		fmt.Printf("Skipping '%s': parent branch '%s' not found\n", branchName, branch.Parent)
+		return false, nil
+	}
+
+	// Fall back to the merge base for branches created before the parent base was recorded
+	oldBase := branch.ParentSHA
+	if oldBase == "" {
+		oldBase, err = mergeBase(g, branch.Parent, branchName)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if oldBase == parentTip {
+		if branch.ParentSHA != parentTip {
+			branch.ParentSHA = parentTip
+			cfg.ManagedBranches[branchName] = branch
+			return true, nil
+		}
+		return false, nil
+	}
+
+	fmt.Printf("Restacking '%s' onto %s...\n", branchName, branch.Parent)
+	if err := rebaseOnto(g, parentTip, oldBase, branchName); err != nil {
+		if !isRebaseInProgress(g) {
+			return false, err
+		}
+
+		op.Current = branchName
+		op.CurrentOnto = parentTip
+		if saveErr := op.Save(); saveErr != nil {
+			return false, saveErr
+		}
+		return false, fmt.Errorf("conflict while restacking '%s' onto '%s'.\nResolve the conflicts and stage them with 'git add', then run 'gt continue' (or 'gt abort' to roll back)", branchName, branch.Parent)
+	}
+
+	branch.ParentSHA = parentTip
+	cfg.ManagedBranches[branchName] = branch
+	return true, nil
+}
+
+// restackDescendants restacks every branch stacked above the given one after
+// its commits were rewritten, returning to it afterwards
+func restackDescendants(g git.Git, cfg *config.Config, command, branchName string) error {
+	descendants := descendantsOf(cfg, branchName)
+	if len(descendants) == 0 {
 		return nil
-	},
+	}
+
+	if err := startRestack(g, cfg, command, descendants, branchName); err != nil {
+		return err
+	}
+	fmt.Printf("Restacked %d branch(es) stacked on '%s'\n", len(descendants), branchName)
+	return nil
+}
+
+// reparentChildren moves the children of a branch onto newParent and returns
+// every branch that was stacked above it, parent-first, ready to be restacked
+func reparentChildren(g git.Git, cfg *config.Config, branchName, newParent string) ([]string, error) {
+	descendants := descendantsOf(cfg, branchName)
+	for _, child := range childrenOf(cfg, branchName) {
+		if err := reparentBranch(g, cfg, child, newParent); err != nil {
+			return nil, err
+		}
+	}
+	return descendants, nil
+}
+
+// reparentBranch makes a managed branch a child of newParent. Its ParentSHA is
+// pinned to the commit it is currently based on, so that the next restack
+// replays only the branch's own commits onto the new parent.
+func reparentBranch(g git.Git, cfg *config.Config, branchName, newParent string) error {
+	base, err := branchBase(g, cfg, branchName)
+	if err != nil {
+		return fmt.Errorf("failed to find base of '%s': %w", branchName, err)
+	}
+
+	branch := cfg.ManagedBranches[branchName]
+	branch.Parent = newParent
+	branch.ParentSHA = base
+	cfg.ManagedBranches[branchName] = branch
+	return nil
 }
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+func TestRestackBranchRebasesOntoParentTip(t *testing.T) {
+	cfg := newStackConfig(map[string]string{"feature": "main"})
+	branch := cfg.ManagedBranches["feature"]
+	branch.ParentSHA = "oldbase"
+	cfg.ManagedBranches["feature"] = branch
+
+	g := git.NewFake()
+	g.Fail("remote get-url origin")
+	g.On("rev-parse --verify main^{commit}", "newtip")
+
+	restacked, err := restackBranch(g, cfg, "feature", &config.Operation{})
+	if err != nil {
+		t.Fatalf("restackBranch returned error: %v", err)
+	}
+	if !restacked {
+		t.Error("Expected branch to be restacked")
+	}
+	if !g.Called("rebase --onto newtip oldbase feature") {
+		t.Errorf("Expected rebase onto the parent tip, got calls: %v", g.Calls)
+	}
+	if cfg.ManagedBranches["feature"].ParentSHA != "newtip" {
+		t.Errorf("Expected ParentSHA to be updated to 'newtip', got '%s'", cfg.ManagedBranches["feature"].ParentSHA)
+	}
+}
+
+func TestRestackBranchSkipsUpToDateBranch(t *testing.T) {
+	cfg := newStackConfig(map[string]string{"feature": "main"})
+	branch := cfg.ManagedBranches["feature"]
+	branch.ParentSHA = "tip"
+	cfg.ManagedBranches["feature"] = branch
+
+	g := git.NewFake()
+	g.Fail("remote get-url origin")
+	g.On("rev-parse --verify main^{commit}", "tip")
+
+	restacked, err := restackBranch(g, cfg, "feature", &config.Operation{})
+	if err != nil {
+		t.Fatalf("restackBranch returned error: %v", err)
+	}
+	if restacked {
+		t.Error("Expected up-to-date branch not to be restacked")
+	}
+	for _, call := range g.Calls {
+		if call[0] == "rebase" {
+			t.Errorf("Expected no rebase, got %v", call)
+		}
+	}
+}
+
+func TestReparentChildrenPinsBase(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+		"d": "a",
+	})
+	branch := cfg.ManagedBranches["d"]
+	branch.ParentSHA = "dbase"
+	cfg.ManagedBranches["d"] = branch
+
+	g := git.NewFake()
+	g.On("merge-base a b", "bbase")
+
+	descendants, err := reparentChildren(g, cfg, "a", "main")
+	if err != nil {
+		t.Fatalf("reparentChildren returned error: %v", err)
+	}
+
+	if expected := []string{"b", "c", "d"}; !reflect.DeepEqual(descendants, expected) {
+		t.Errorf("reparentChildren() = %v; want %v", descendants, expected)
+	}
+	if b := cfg.ManagedBranches["b"]; b.Parent != "main" || b.ParentSHA != "bbase" {
+		t.Errorf("Expected 'b' on main from 'bbase', got %+v", b)
+	}
+	if d := cfg.ManagedBranches["d"]; d.Parent != "main" || d.ParentSHA != "dbase" {
+		t.Errorf("Expected 'd' on main from 'dbase', got %+v", d)
+	}
+	if cfg.ManagedBranches["c"].Parent != "b" {
+		t.Errorf("Expected grandchild 'c' to stay on 'b', got %+v", cfg.ManagedBranches["c"])
+	}
+}
@@ -2,13 +2,30 @@ package commands
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/git"
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "gt",
 	Short: "A Git workflow CLI tool",
 	Long: `gt is a CLI tool that augments git with opinionated workflow commands.
-It helps manage branches, track settings per workspace, and streamline common git operations.`,
+It helps manage branches, track settings per workspace, and streamline common git operations.
+Set GT_TRACE=1 to print every git invocation and how long it took.`,
+}
+
+// newGit creates the git runner handed to every command; tests replace it with a fake
+var newGit = func() git.Git {
+	return git.NewExec("")
+}
+
+// gitRunFunc is the signature of a command implementation that runs git through g
+type gitRunFunc func(g git.Git, cmd *cobra.Command, args []string) error
+
+// withGit adapts a gitRunFunc into a cobra RunE, handing it a new git runner
+func withGit(run gitRunFunc) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		return run(newGit(), cmd, args)
+	}
 }
 
 // Execute runs the root command
@@ -25,4 +42,23 @@ func init() {
 	rootCmd.AddCommand(syncCmd)
 	rootCmd.AddCommand(restackCmd)
 	rootCmd.AddCommand(submitCmd)
+	rootCmd.AddCommand(continueCmd)
+	rootCmd.AddCommand(abortCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(oplogCmd)
+	rootCmd.AddCommand(logCmd)
+	rootCmd.AddCommand(upCmd)
+	rootCmd.AddCommand(downCmd)
+	rootCmd.AddCommand(topCmd)
+	rootCmd.AddCommand(bottomCmd)
+	rootCmd.AddCommand(storageCmd)
+	rootCmd.AddCommand(trackCmd)
+	rootCmd.AddCommand(untrackCmd)
+	rootCmd.AddCommand(moveCmd)
+	rootCmd.AddCommand(foldCmd)
+	rootCmd.AddCommand(splitCmd)
+	rootCmd.AddCommand(squashCmd)
+	rootCmd.AddCommand(reorderCmd)
+	rootCmd.AddCommand(absorbCmd)
+	rootCmd.AddCommand(renameCmd)
 }
@@ -0,0 +1,355 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var (
+	splitByCommit bool
+	splitByHunk   bool
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split (--by-commit | --by-hunk)",
+	Short: "Split the current branch into several stacked branches",
+	Long: `Split the current branch into a stack of branches.
+--by-commit lists the branch's commits and asks after which ones to start a new branch; each range of commits becomes its own branch.
+--by-hunk unstages the branch's changes and lets you pick hunks (git add --patch) for each new branch in turn, committing them as you go.
+The new branches are stacked below the current branch, which keeps its name, pull request and children; branches stacked on it are restacked if needed.`,
+	Args: cobra.NoArgs,
+	RunE: recordOperation(runSplit),
+}
+
+// splitPart is one of the branches a split produces, from the bottom of the stack up
+type splitPart struct {
+	Name        string
+	Tip         string
+	Description string
+}
+
+// branchCommit is one of a branch's own commits
+type branchCommit struct {
+	SHA     string
+	Subject string
+}
+
+func runSplit(g git.Git, cmd *cobra.Command, args []string) error {
+	if splitByCommit == splitByHunk {
+		return fmt.Errorf("choose exactly one of --by-commit or --by-hunk")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+	if _, managed := cfg.ManagedBranches[currentBranch]; !managed {
+		return fmt.Errorf("branch '%s' is not tracked by gt", currentBranch)
+	}
+	if err := ensureNoOperationInProgress(); err != nil {
+		return err
+	}
+
+	base, err := branchBase(g, cfg, currentBranch)
+	if err != nil {
+		return fmt.Errorf("failed to find base of '%s': %w", currentBranch, err)
+	}
+	originalTip, err := revParse(g, currentBranch)
+	if err != nil {
+		return err
+	}
+
+	var parts []splitPart
+	if splitByCommit {
+		parts, err = chooseCommitSplit(g, currentBranch, base)
+	} else {
+		parts, err = splitHunks(g, currentBranch, base)
+	}
+	if err != nil {
+		return err
+	}
+	if len(parts) < 2 {
+		fmt.Println("Nothing to split")
+		return nil
+	}
+
+	// Create the new branches below the current one; its own tip is updated last
+	for _, part := range parts[:len(parts)-1] {
+		if _, err := g.Run("branch", part.Name, part.Tip); err != nil {
+			return fmt.Errorf("failed to create branch '%s': %w", part.Name, err)
+		}
+	}
+	if err := resetBranchTo(g, currentBranch, parts[len(parts)-1].Tip); err != nil {
+		return err
+	}
+
+	applySplit(cfg, currentBranch, base, parts)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	for _, part := range parts[:len(parts)-1] {
+		fmt.Printf("Created branch '%s'\n", part.Name)
+	}
+	fmt.Printf("Split '%s' into %d branches\n", currentBranch, len(parts))
+
+	// Splitting by hunk rewrites the branch's commits, so its children need restacking
+	if parts[len(parts)-1].Tip == originalTip {
+		return nil
+	}
+	return restackDescendants(g, cfg, "split", currentBranch)
+}
+
+// applySplit records the branches produced by a split in config. Each part is
+// stacked on the previous one, the first on the original branch's parent, and
+// the last part is the original branch itself.
+func applySplit(cfg *config.Config, branchName, base string, parts []splitPart) {
+	original := cfg.ManagedBranches[branchName]
+	parent, parentSHA := original.Parent, base
+
+	for _, part := range parts[:len(parts)-1] {
+		cfg.ManagedBranches[part.Name] = config.Branch{
+			Name:        part.Name,
+			Parent:      parent,
+			Description: part.Description,
+			ParentSHA:   parentSHA,
+		}
+		parent, parentSHA = part.Name, part.Tip
+	}
+
+	original.Parent = parent
+	original.ParentSHA = parentSHA
+	cfg.ManagedBranches[branchName] = original
+}
+
+// listBranchCommits returns the commits in base..branch, oldest first
+func listBranchCommits(g git.Git, branchName, base string) ([]branchCommit, error) {
+	output, err := g.Run("log", "--reverse", "--format=%H %s", base+".."+branchName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits of '%s': %w", branchName, err)
+	}
+
+	commits := []branchCommit{}
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		sha, subject, _ := strings.Cut(line, " ")
+		commits = append(commits, branchCommit{SHA: sha, Subject: subject})
+	}
+	return commits, nil
+}
+
+// chooseCommitSplit asks after which commits new branches should start and
+// names each resulting branch
+func chooseCommitSplit(g git.Git, branchName, base string) ([]splitPart, error) {
+	commits, err := listBranchCommits(g, branchName, base)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) < 2 {
+		return nil, fmt.Errorf("branch '%s' has %d commit(s); --by-commit needs at least 2", branchName, len(commits))
+	}
+
+	fmt.Printf("Commits of '%s', oldest first:\n", branchName)
+	for i, commit := range commits {
+		fmt.Printf("  %d) %s %s\n", i+1, shortSHA(commit.SHA), commit.Subject)
+	}
+	response, err := promptLine(fmt.Sprintf("End a branch after which commits? (numbers 1-%d separated by spaces): ", len(commits)-1))
+	if err != nil {
+		return nil, err
+	}
+	points, err := parseSplitPoints(response, len(commits))
+	if err != nil {
+		return nil, err
+	}
+
+	parts := []splitPart{}
+	taken := map[string]bool{}
+	start := 0
+	for _, point := range points {
+		segment := commits[start:point]
+		name, err := promptBranchName(g, segment[len(segment)-1].Subject, taken)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, splitPart{Name: name, Tip: segment[len(segment)-1].SHA, Description: segment[0].Subject})
+		start = point
+	}
+	parts = append(parts, splitPart{Name: branchName, Tip: commits[len(commits)-1].SHA})
+	return parts, nil
+}
+
+// parseSplitPoints parses the commit numbers after which a new branch starts.
+// Numbers are 1-based and must leave at least one commit for the last branch.
+func parseSplitPoints(input string, commitCount int) ([]int, error) {
+	seen := map[int]bool{}
+	points := []int{}
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ' ' || r == ',' }) {
+		point, err := strconv.Atoi(field)
+		if err != nil || point < 1 || point >= commitCount {
+			return nil, fmt.Errorf("invalid commit number '%s'; expected 1-%d", field, commitCount-1)
+		}
+		if !seen[point] {
+			seen[point] = true
+			points = append(points, point)
+		}
+	}
+	sort.Ints(points)
+	return points, nil
+}
+
+// promptBranchName asks for the name of a new branch, suggesting one derived
+// from a commit subject. The name must be valid and not in use.
+func promptBranchName(g git.Git, subject string, taken map[string]bool) (string, error) {
+	suggested := sanitizeBranchName(subject)
+	for {
+		name, err := promptLine(fmt.Sprintf("Name for the branch ending at '%s' [%s]: ", subject, suggested))
+		if err != nil {
+			return "", err
+		}
+		if name == "" {
+			name = suggested
+		}
+
+		if _, err := g.Run("check-ref-format", "--branch", name); err != nil {
+			fmt.Printf("Invalid branch name '%s'\n", name)
+			continue
+		}
+		if _, err := revParse(g, "refs/heads/"+name); err == nil || taken[name] {
+			fmt.Printf("Branch '%s' already exists\n", name)
+			continue
+		}
+		taken[name] = true
+		return name, nil
+	}
+}
+
+// splitHunks unstages the branch's changes on a detached HEAD and commits them
+// again in rounds, each from the hunks picked with 'git add --patch'. Every
+// round but the last becomes a new branch; the last keeps the branch's name.
+// The branch itself is only moved once the caller applies the split.
+func splitHunks(g git.Git, branchName, base string) ([]splitPart, error) {
+	clean, err := isWorkingTreeClean(g)
+	if err != nil {
+		return nil, err
+	}
+	if !clean {
+		return nil, fmt.Errorf("commit or stash your changes before splitting a branch")
+	}
+
+	message, err := g.Run("log", "-1", "--format=%B", branchName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit message: %w", err)
+	}
+
+	if _, err := g.Run("checkout", "--quiet", "--detach", branchName); err != nil {
+		return nil, fmt.Errorf("failed to detach HEAD: %w", err)
+	}
+	// Put the branch back as it was if the split does not complete
+	completed := false
+	defer func() {
+		if !completed {
+			g.Run("reset", "--quiet", "--hard")
+			g.Run("checkout", "--quiet", branchName)
+		}
+	}()
+
+	// Unstage everything since base; new files are kept as intent-to-add so
+	// that 'git add --patch' offers them too
+	if _, err := g.Run("reset", "--quiet", "-N", base); err != nil {
+		return nil, fmt.Errorf("failed to unstage changes: %w", err)
+	}
+
+	parts := []splitPart{}
+	taken := map[string]bool{}
+	for hasUnstagedChanges(g) {
+		fmt.Printf("Select the hunks for branch %d of the split:\n", len(parts)+1)
+		if err := stagePatch(g); err != nil {
+			return nil, err
+		}
+
+		if !hasStagedChanges(g) {
+			fmt.Printf("No hunks selected; the remaining changes stay in '%s'\n", branchName)
+			break
+		}
+		if !hasUnstagedChanges(g) {
+			// Everything left was picked, so this round is the branch itself
+			break
+		}
+
+		subject, err := promptLine("Commit message for the selected hunks: ")
+		if err != nil {
+			return nil, err
+		}
+		if subject == "" {
+			return nil, fmt.Errorf("a commit message is required")
+		}
+		name, err := promptBranchName(g, subject, taken)
+		if err != nil {
+			return nil, err
+		}
+		if err := createCommit(g, subject); err != nil {
+			return nil, err
+		}
+		tip, err := revParse(g, "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, splitPart{Name: name, Tip: tip, Description: subject})
+	}
+
+	if len(parts) == 0 {
+		return nil, nil
+	}
+
+	// The remaining changes are committed with the branch's original message
+	if err := stageAllFiles(g); err != nil {
+		return nil, err
+	}
+	if err := createCommit(g, message); err != nil {
+		return nil, err
+	}
+	tip, err := revParse(g, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	parts = append(parts, splitPart{Name: branchName, Tip: tip})
+
+	// The new tip has the same tree as the old one, so the branch can be
+	// checked out now and moved to it afterwards
+	if err := checkoutBranch(g, branchName); err != nil {
+		return nil, err
+	}
+	completed = true
+	return parts, nil
+}
+
+// hasUnstagedChanges reports whether the working tree differs from the index
+func hasUnstagedChanges(g git.Git) bool {
+	_, err := g.Run("diff", "--quiet")
+	return err != nil
+}
+
+// hasStagedChanges reports whether the index differs from HEAD
+func hasStagedChanges(g git.Git) bool {
+	_, err := g.Run("diff", "--cached", "--quiet")
+	return err != nil
+}
+
+func init() {
+	splitCmd.Flags().BoolVar(&splitByCommit, "by-commit", false, "Split at commit boundaries")
+	splitCmd.Flags().BoolVar(&splitByHunk, "by-hunk", false, "Split by interactively assigning hunks to new branches")
+	splitCmd.MarkFlagsMutuallyExclusive("by-commit", "by-hunk")
+}
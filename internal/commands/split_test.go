@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSplitPoints(t *testing.T) {
+	points, err := parseSplitPoints("3, 1 3", 4)
+	if err != nil {
+		t.Fatalf("parseSplitPoints returned error: %v", err)
+	}
+	if !reflect.DeepEqual(points, []int{1, 3}) {
+		t.Errorf("Expected points [1 3], got %v", points)
+	}
+
+	for _, input := range []string{"0", "4", "x"} {
+		if _, err := parseSplitPoints(input, 4); err == nil {
+			t.Errorf("Expected an error for '%s'", input)
+		}
+	}
+}
+
+func TestApplySplit(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+	})
+	b := cfg.ManagedBranches["b"]
+	b.PRNumber = 7
+	cfg.ManagedBranches["b"] = b
+
+	applySplit(cfg, "b", "atip", []splitPart{
+		{Name: "b1", Tip: "tip1", Description: "first"},
+		{Name: "b2", Tip: "tip2", Description: "second"},
+		{Name: "b", Tip: "tip3"},
+	})
+
+	if b1 := cfg.ManagedBranches["b1"]; b1.Parent != "a" || b1.ParentSHA != "atip" || b1.Description != "first" {
+		t.Errorf("Expected 'b1' on 'a' from 'atip', got %+v", b1)
+	}
+	if b2 := cfg.ManagedBranches["b2"]; b2.Parent != "b1" || b2.ParentSHA != "tip1" {
+		t.Errorf("Expected 'b2' on 'b1' from 'tip1', got %+v", b2)
+	}
+	if b := cfg.ManagedBranches["b"]; b.Parent != "b2" || b.ParentSHA != "tip2" || b.PRNumber != 7 {
+		t.Errorf("Expected 'b' on 'b2' from 'tip2' keeping its PR, got %+v", b)
+	}
+	if c := cfg.ManagedBranches["c"]; c.Parent != "b" {
+		t.Errorf("Expected 'c' to stay on 'b', got %+v", c)
+	}
+}
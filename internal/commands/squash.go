@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var squashMessage string
+
+var squashCmd = &cobra.Command{
+	Use:   "squash",
+	Short: "Squash the current branch's commits into one",
+	Long:  `Squash every commit of the current branch since its parent into a single commit. The squashed commit keeps the author and date of the first commit, and its message defaults to the branch's description (the message it was created with); pass -m to use a different one. Branches stacked on the current one are restacked afterwards, so the working tree must be clean if there are any; otherwise uncommitted changes are left untouched. If a rebase stops on a conflict, resolve it and run 'gt continue', or run 'gt abort' to roll back. Will not run on trunk branch.`,
+	Args:  cobra.NoArgs,
+	RunE:  recordOperation(runSquash),
+}
+
+func runSquash(g git.Git, cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+	if currentBranch == cfg.TrunkBranch {
+		return fmt.Errorf("Error: gt squash cannot be run on %s", cfg.TrunkBranch)
+	}
+	if _, managed := cfg.ManagedBranches[currentBranch]; !managed {
+		return fmt.Errorf("branch '%s' is not tracked by gt", currentBranch)
+	}
+	if err := ensureNoOperationInProgress(); err != nil {
+		return err
+	}
+	// Restacking the branches above needs a clean working tree; check before
+	// the branch is rewritten, so a refusal leaves nothing half done
+	if len(descendantsOf(cfg, currentBranch)) > 0 {
+		clean, err := isWorkingTreeClean(g)
+		if err != nil {
+			return err
+		}
+		if !clean {
+			return fmt.Errorf("commit or stash your changes first; the branches stacked on '%s' have to be restacked", currentBranch)
+		}
+	}
+
+	squashed, err := squashBranch(g, cfg, currentBranch, squashMessage)
+	if err != nil {
+		return err
+	}
+	if !squashed {
+		fmt.Printf("Branch '%s' already has a single commit\n", currentBranch)
+		return nil
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf("✔ gt squash: squashed '%s' into one commit\n", currentBranch)
+
+	return restackDescendants(g, cfg, "squash", currentBranch)
+}
+
+// squashBranch replaces the commits of a checked-out branch since its base
+// with one commit of the same tree. The message defaults to the branch's
+// description; a new message also becomes the description. Returns false if
+// there was nothing to squash.
+func squashBranch(g git.Git, cfg *config.Config, branchName, message string) (bool, error) {
+	base, err := branchBase(g, cfg, branchName)
+	if err != nil {
+		return false, fmt.Errorf("failed to find base of '%s': %w", branchName, err)
+	}
+	count, err := countCommits(g, base, branchName)
+	if err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, fmt.Errorf("branch '%s' has no commits to squash", branchName)
+	}
+	if count == 1 && message == "" {
+		return false, nil
+	}
+
+	// The squashed commit takes the author of the branch's first commit
+	commits, err := listBranchCommits(g, branchName, base)
+	if err != nil {
+		return false, err
+	}
+	if len(commits) == 0 {
+		return false, fmt.Errorf("branch '%s' has no commits to squash", branchName)
+	}
+	env, err := authorEnv(g, commits[0].SHA)
+	if err != nil {
+		return false, err
+	}
+
+	branch := cfg.ManagedBranches[branchName]
+	if message != "" {
+		branch.Description = message
+	} else if branch.Description != "" {
+		message = branch.Description
+	} else {
+		// Fall back to the first commit's subject for branches without a description
+		message = commits[0].Subject
+	}
+
+	tree, err := g.Run("rev-parse", branchName+"^{tree}")
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve tree of '%s': %w", branchName, err)
+	}
+	squashed, err := g.WithEnv(env...).Run("commit-tree", tree, "-p", base, "-m", message)
+	if err != nil {
+		return false, fmt.Errorf("failed to create squashed commit: %w", err)
+	}
+
+	// Moving the ref leaves the index and working tree as they are, like 'git reset --soft'
+	if err := resetBranchTo(g, branchName, squashed); err != nil {
+		return false, err
+	}
+
+	cfg.ManagedBranches[branchName] = branch
+	return true, nil
+}
+
+func init() {
+	squashCmd.Flags().StringVarP(&squashMessage, "message", "m", "", "Message for the squashed commit (defaults to the branch description)")
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+func TestSquashBranchUsesDescription(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+	})
+	setParentSHA(cfg, "b", "atip")
+	b := cfg.ManagedBranches["b"]
+	b.Description = "Add b"
+	cfg.ManagedBranches["b"] = b
+
+	g := git.NewFake()
+	g.On("rev-list --count atip..b", "3")
+	g.On("log --reverse --format=%H %s atip..b", "b1 First\nb2 Second\nb3 Third")
+	g.On("log -1 --format=%an%x00%ae%x00%ad --date=raw b1", "Ada\x00ada@example.com\x001700000000 +0000")
+	g.On("rev-parse b^{tree}", "btree")
+	g.On("commit-tree btree -p atip -m Add b", "squashed")
+
+	squashed, err := squashBranch(g, cfg, "b", "")
+	if err != nil {
+		t.Fatalf("squashBranch returned error: %v", err)
+	}
+	if !squashed {
+		t.Fatal("Expected the branch to be squashed")
+	}
+	for i, call := range g.Calls {
+		if call[0] != "commit-tree" {
+			continue
+		}
+		expected := []string{"GIT_AUTHOR_NAME=Ada", "GIT_AUTHOR_EMAIL=ada@example.com", "GIT_AUTHOR_DATE=1700000000 +0000"}
+		if !reflect.DeepEqual(g.Envs[i], expected) {
+			t.Errorf("Expected the squashed commit to keep the first commit's author, got env %v", g.Envs[i])
+		}
+	}
+	if !g.Called("update-ref refs/heads/b squashed") {
+		t.Errorf("Expected 'b' to be moved to the squashed commit, got calls: %v", g.Calls)
+	}
+	if b := cfg.ManagedBranches["b"]; b.Description != "Add b" || b.ParentSHA != "atip" {
+		t.Errorf("Expected 'b' to keep its description and base, got %+v", b)
+	}
+}
+
+func TestSquashBranchWithMessage(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+	})
+	setParentSHA(cfg, "b", "atip")
+
+	g := git.NewFake()
+	g.On("rev-list --count atip..b", "1")
+	g.On("log --reverse --format=%H %s atip..b", "b1 Add b")
+	g.On("rev-parse b^{tree}", "btree")
+	g.On("commit-tree btree -p atip -m Better message", "squashed")
+
+	squashed, err := squashBranch(g, cfg, "b", "Better message")
+	if err != nil {
+		t.Fatalf("squashBranch returned error: %v", err)
+	}
+	if !squashed {
+		t.Fatal("Expected a single commit to be reworded")
+	}
+	if b := cfg.ManagedBranches["b"]; b.Description != "Better message" {
+		t.Errorf("Expected the description to be updated, got %+v", b)
+	}
+}
+
+func TestSquashBranchWithSingleCommit(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+	})
+	setParentSHA(cfg, "a", "mainbase")
+
+	g := git.NewFake()
+	g.On("rev-list --count mainbase..a", "1")
+
+	squashed, err := squashBranch(g, cfg, "a", "")
+	if err != nil {
+		t.Fatalf("squashBranch returned error: %v", err)
+	}
+	if squashed {
+		t.Error("Expected nothing to squash")
+	}
+	if g.Called("rev-parse a^{tree}") {
+		t.Error("Expected no commit to be created")
+	}
+}
+
+func TestSquashRefusesDirtyTreeWithDescendants(t *testing.T) {
+	useScratchRepo(t)
+
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+	})
+	setParentSHA(cfg, "a", "maintip")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	g := git.NewFake()
+	g.On("rev-parse --abbrev-ref HEAD", "a")
+	g.On("status --porcelain --untracked-files=no", " M f")
+
+	if err := runSquash(g, nil, nil); err == nil {
+		t.Fatal("Expected runSquash to refuse a dirty working tree")
+	}
+	for _, call := range g.Calls {
+		if call[0] == "commit-tree" || call[0] == "update-ref" {
+			t.Errorf("Expected the branch to be left alone, got call: %v", call)
+		}
+	}
+}
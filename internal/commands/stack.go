@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/th1nkful/cli-gt/internal/config"
+)
+
+// childrenOf returns the managed branches whose parent is the given branch, sorted by name
+func childrenOf(cfg *config.Config, parent string) []string {
+	children := []string{}
+	for name, branch := range cfg.ManagedBranches {
+		if branch.Parent == parent {
+			children = append(children, name)
+		}
+	}
+	sort.Strings(children)
+	return children
+}
+
+// descendantsOf returns every managed branch stacked above the given branch,
+// ordered so that each branch comes after its parent
+func descendantsOf(cfg *config.Config, branch string) []string {
+	return collectDescendants(cfg, branch, map[string]bool{branch: true})
+}
+
+// collectDescendants walks the branches above branch, skipping those already
+// seen so that a parent cycle in the config cannot recurse forever
+func collectDescendants(cfg *config.Config, branch string, seen map[string]bool) []string {
+	descendants := []string{}
+	for _, child := range childrenOf(cfg, branch) {
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		descendants = append(descendants, child)
+		descendants = append(descendants, collectDescendants(cfg, child, seen)...)
+	}
+	return descendants
+}
+
+// ancestorsOf returns the managed branches below the given branch, ordered
+// from the one closest to trunk up to the branch's direct parent
+func ancestorsOf(cfg *config.Config, branch string) []string {
+	ancestors := []string{}
+	current := branch
+	for range cfg.ManagedBranches {
+		parent := cfg.ManagedBranches[current].Parent
+		if _, managed := cfg.ManagedBranches[parent]; !managed {
+			break
+		}
+		ancestors = append([]string{parent}, ancestors...)
+		current = parent
+	}
+	return ancestors
+}
+
+// currentStack returns every managed branch in the stack containing the given
+// branch: its ancestors, the branch itself and its descendants, parent-first
+func currentStack(cfg *config.Config, branch string) []string {
+	stack := ancestorsOf(cfg, branch)
+	stack = append(stack, branch)
+	return append(stack, descendantsOf(cfg, branch)...)
+}
+
+// topoOrder returns all managed branches ordered so that every branch comes
+// after its parent. Branches whose parent is neither trunk nor managed are
+// treated as roots. Returns an error if the parent links contain a cycle.
+func topoOrder(cfg *config.Config) ([]string, error) {
+	roots := []string{}
+	for name, branch := range cfg.ManagedBranches {
+		if branch.Parent == cfg.TrunkBranch {
+			roots = append(roots, name)
+			continue
+		}
+		if _, managed := cfg.ManagedBranches[branch.Parent]; !managed {
+			roots = append(roots, name)
+		}
+	}
+	sort.Strings(roots)
+
+	order := []string{}
+	for _, root := range roots {
+		order = append(order, root)
+		order = append(order, descendantsOf(cfg, root)...)
+	}
+
+	if len(order) != len(cfg.ManagedBranches) {
+		return nil, fmt.Errorf("managed branches contain a parent cycle")
+	}
+	return order, nil
+}
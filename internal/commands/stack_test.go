@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/config"
+)
+
+func newStackConfig(parents map[string]string) *config.Config {
+	cfg := &config.Config{
+		TrunkBranch:     "main",
+		ManagedBranches: make(map[string]config.Branch),
+	}
+	for name, parent := range parents {
+		cfg.ManagedBranches[name] = config.Branch{Name: name, Parent: parent}
+	}
+	return cfg
+}
+
+func setParentSHA(cfg *config.Config, name, sha string) {
+	branch := cfg.ManagedBranches[name]
+	branch.ParentSHA = sha
+	cfg.ManagedBranches[name] = branch
+}
+
+func TestTopoOrder(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"e": "d",
+		"d": "c",
+		"c": "b",
+		"b": "a",
+		"a": "main",
+		"x": "a",
+	})
+
+	order, err := topoOrder(cfg)
+	if err != nil {
+		t.Fatalf("topoOrder returned error: %v", err)
+	}
+
+	expected := []string{"a", "b", "c", "d", "e", "x"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("topoOrder() = %v; want %v", order, expected)
+	}
+}
+
+func TestTopoOrderUnknownParentIsRoot(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "someone-elses-branch",
+		"b": "a",
+	})
+
+	order, err := topoOrder(cfg)
+	if err != nil {
+		t.Fatalf("topoOrder returned error: %v", err)
+	}
+
+	expected := []string{"a", "b"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("topoOrder() = %v; want %v", order, expected)
+	}
+}
+
+func TestTopoOrderDetectsCycle(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "c",
+		"c": "b",
+	})
+
+	if _, err := topoOrder(cfg); err == nil {
+		t.Error("Expected topoOrder to report a parent cycle")
+	}
+}
+
+func TestDescendantsOf(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+		"d": "a",
+	})
+
+	descendants := descendantsOf(cfg, "a")
+	expected := []string{"b", "c", "d"}
+	if !reflect.DeepEqual(descendants, expected) {
+		t.Errorf("descendantsOf(a) = %v; want %v", descendants, expected)
+	}
+
+	if len(descendantsOf(cfg, "c")) != 0 {
+		t.Errorf("Expected no descendants for leaf branch 'c'")
+	}
+}
+
+func TestDescendantsOfStopsAtCycle(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "c",
+		"c": "b",
+		"d": "c",
+	})
+
+	descendants := descendantsOf(cfg, "b")
+	expected := []string{"c", "d"}
+	if !reflect.DeepEqual(descendants, expected) {
+		t.Errorf("descendantsOf(b) = %v; want %v", descendants, expected)
+	}
+}
+
+func TestCurrentStack(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a":     "main",
+		"b":     "a",
+		"c":     "b",
+		"d":     "c",
+		"other": "main",
+	})
+
+	if ancestors := ancestorsOf(cfg, "c"); !reflect.DeepEqual(ancestors, []string{"a", "b"}) {
+		t.Errorf("ancestorsOf(c) = %v; want [a b]", ancestors)
+	}
+
+	stack := currentStack(cfg, "b")
+	expected := []string{"a", "b", "c", "d"}
+	if !reflect.DeepEqual(stack, expected) {
+		t.Errorf("currentStack(b) = %v; want %v", stack, expected)
+	}
+}
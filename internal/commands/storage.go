@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var storageCmd = &cobra.Command{
+	Use:   "storage [file|refs]",
+	Short: "Show or change where branch metadata is stored",
+	Long: `Show or change where gt keeps the metadata of managed branches.
+'file' keeps it in .git/gt/config.json, local to this workspace. 'refs' keeps each branch's metadata in a blob under refs/gt-meta/<branch>, which 'gt submit' pushes to origin alongside the branch so that the stack can be recovered in another clone with:
+  git fetch origin 'refs/gt-meta/*:refs/gt-meta/*'`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{config.StorageFile, config.StorageRefs},
+	RunE:      recordOperation(runStorage),
+}
+
+func runStorage(g git.Git, cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	current := cfg.Storage
+	if current == "" {
+		current = config.StorageFile
+	}
+
+	if len(args) == 0 {
+		fmt.Printf("Branch metadata is stored in: %s\n", current)
+		return nil
+	}
+
+	target := args[0]
+	if target != config.StorageFile && target != config.StorageRefs {
+		return fmt.Errorf("unknown storage backend '%s'; expected '%s' or '%s'", target, config.StorageFile, config.StorageRefs)
+	}
+	if target == current {
+		fmt.Printf("Branch metadata is already stored in: %s\n", current)
+		return nil
+	}
+
+	cfg.Storage = target
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	// The metadata now lives in the config file, so the refs would only go stale
+	if current == config.StorageRefs {
+		files, err := config.NewFileStore()
+		if err != nil {
+			return err
+		}
+		if err := config.NewRefStore(g, files).SaveBranches(map[string]config.Branch{}); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Moved metadata of %d branch(es) to: %s\n", len(cfg.ManagedBranches), target)
+	return nil
+}
@@ -4,14 +4,175 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+	"github.com/th1nkful/cli-gt/internal/github"
+)
+
+var (
+	submitDraft bool
 )
 
 var submitCmd = &cobra.Command{
 	Use:   "submit",
 	Short: "Submit the current branch for review",
-	Long:  `Submit the current branch for review (e.g., create/update a pull request). Will not run on trunk branch.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("submit command - not yet implemented")
+	Long: `Submit the current stack for review. Every branch in the stack containing the current branch is pushed to origin with --force-with-lease, and a GitHub pull request is created or updated for each one, targeting the branch's parent. Will not run on trunk branch.
+Requires GITHUB_TOKEN (or GH_TOKEN); set GT_GITHUB_API_URL to use a different API endpoint.`,
+	Args: cobra.NoArgs,
+	RunE: recordOperation(runSubmit),
+}
+
+func runSubmit(g git.Git, cmd *cobra.Command, args []string) error {
+	// Check if we're on trunk branch and load config
+	onTrunk, cfg, err := isOnTrunkBranch(g)
+	if err != nil {
+		return err
+	}
+	if onTrunk {
+		return fmt.Errorf("submit command cannot be run on trunk branch (%s)", cfg.TrunkBranch)
+	}
+
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return err
+	}
+	if _, managed := cfg.ManagedBranches[currentBranch]; !managed {
+		return fmt.Errorf("branch '%s' is not tracked by gt", currentBranch)
+	}
+
+	stack := currentStack(cfg, currentBranch)
+	for _, branchName := range stack {
+		if needsRestack(g, cfg, branchName) {
+			return fmt.Errorf("branch '%s' is not based on its parent's latest commit; run 'gt restack' first", branchName)
+		}
+	}
+
+	client, err := newGitHubClient(g)
+	if err != nil {
+		return err
+	}
+
+	for _, branchName := range stack {
+		fmt.Printf("Pushing '%s'...\n", branchName)
+		if err := pushBranch(g, branchName); err != nil {
+			return err
+		}
+
+		pull, created, err := submitPullRequest(g, client, cfg, branchName, submitDraft)
+		if err != nil {
+			return err
+		}
+
+		branch := cfg.ManagedBranches[branchName]
+		branch.PRNumber = pull.Number
+		branch.PRURL = pull.HTMLURL
+		cfg.ManagedBranches[branchName] = branch
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		if err := pushBranchMetadata(g, cfg, branchName); err != nil {
+			return err
+		}
+
+		action := "Updated"
+		if created {
+			action = "Created"
+		}
+		fmt.Printf("%s PR #%d for '%s' (base: %s): %s\n", action, pull.Number, branchName, branch.Parent, pull.HTMLURL)
+	}
+
+	return nil
+}
+
+// newGitHubClient creates a GitHub client for the repository that origin points at
+func newGitHubClient(g git.Git) (*github.Client, error) {
+	remoteURL, err := g.Run("remote", "get-url", "origin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get origin URL: %w", err)
+	}
+
+	owner, repo, err := github.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return github.NewClientFromEnv(owner, repo)
+}
+
+// pushBranch pushes a branch to origin, refusing to overwrite commits pushed by someone else
+func pushBranch(g git.Git, branchName string) error {
+	if _, err := g.Run("push", "--force-with-lease", "--set-upstream", "origin", branchName+":"+branchName); err != nil {
+		return fmt.Errorf("failed to push branch '%s': %w", branchName, err)
+	}
+	return nil
+}
+
+// pushBranchMetadata pushes a branch's metadata ref to origin when branch
+// metadata is kept in refs, so that the stack can be recovered from another clone
+func pushBranchMetadata(g git.Git, cfg *config.Config, branchName string) error {
+	if cfg.Storage != config.StorageRefs {
 		return nil
-	},
+	}
+	ref := config.MetaRef(branchName)
+	if _, err := g.Run("push", "--force", "origin", ref+":"+ref); err != nil {
+		return fmt.Errorf("failed to push metadata for '%s': %w", branchName, err)
+	}
+	return nil
+}
+
+// submitPullRequest makes sure a branch has an open pull request whose base is
+// the branch's parent. It reuses the pull request recorded in config or an
+// open one for the same head branch before creating a new one.
+// Returns the pull request and whether it was newly created.
+func submitPullRequest(g git.Git, client *github.Client, cfg *config.Config, branchName string, draft bool) (*github.PullRequest, bool, error) {
+	branch := cfg.ManagedBranches[branchName]
+
+	var pull *github.PullRequest
+	if branch.PRNumber != 0 {
+		existing, err := client.GetPullRequest(branch.PRNumber)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get PR #%d: %w", branch.PRNumber, err)
+		}
+		// A closed or merged pull request cannot be reused
+		if existing.State == "open" {
+			pull = existing
+		}
+	}
+	if pull == nil {
+		existing, err := client.FindPullRequest(branchName)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to look up PR for '%s': %w", branchName, err)
+		}
+		pull = existing
+	}
+
+	if pull == nil {
+		title := branch.Description
+		if title == "" {
+			subject, err := g.Run("log", "-1", "--format=%s", branchName)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to read commit message: %w", err)
+			}
+			title = subject
+		}
+
+		created, err := client.CreatePullRequest(branchName, branch.Parent, title, "", draft)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to create PR for '%s': %w", branchName, err)
+		}
+		return created, true, nil
+	}
+
+	if pull.Base.Ref != branch.Parent {
+		updated, err := client.UpdatePullRequestBase(pull.Number, branch.Parent)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to update base of PR #%d: %w", pull.Number, err)
+		}
+		pull = updated
+	}
+	return pull, false, nil
+}
+
+func init() {
+	submitCmd.Flags().BoolVarP(&submitDraft, "draft", "d", false, "Create new pull requests as drafts")
 }
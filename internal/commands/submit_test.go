@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+	"github.com/th1nkful/cli-gt/internal/github"
+)
+
+func TestSubmitPullRequestCreatesThenRetargets(t *testing.T) {
+	server := github.NewFakeServer("owner", "repo", "token")
+	defer server.Close()
+	client := github.NewClient(server.URL, "token", "owner", "repo")
+
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+	})
+	branch := cfg.ManagedBranches["b"]
+	branch.Description = "Add b"
+	cfg.ManagedBranches["b"] = branch
+
+	g := git.NewFake()
+	pull, created, err := submitPullRequest(g, client, cfg, "b", false)
+	if err != nil {
+		t.Fatalf("submitPullRequest returned error: %v", err)
+	}
+	if !created {
+		t.Error("Expected a new pull request to be created")
+	}
+	if pull.Base.Ref != "a" || pull.Title != "Add b" {
+		t.Errorf("Expected PR 'Add b' based on 'a', got %+v", pull)
+	}
+
+	// Reparent the branch and record the PR as submit does
+	branch = cfg.ManagedBranches["b"]
+	branch.Parent = "main"
+	branch.PRNumber = pull.Number
+	cfg.ManagedBranches["b"] = branch
+
+	pull, created, err = submitPullRequest(g, client, cfg, "b", false)
+	if err != nil {
+		t.Fatalf("submitPullRequest returned error: %v", err)
+	}
+	if created {
+		t.Error("Expected the existing pull request to be reused")
+	}
+	if stored, _ := server.PullRequest(pull.Number); stored.Base.Ref != "main" {
+		t.Errorf("Expected PR base to be updated to 'main', got '%s'", stored.Base.Ref)
+	}
+	if server.PullRequestCount() != 1 {
+		t.Errorf("Expected a single pull request, got %d", server.PullRequestCount())
+	}
+}
+
+func TestSubmitPullRequestFindsExistingByHead(t *testing.T) {
+	server := github.NewFakeServer("owner", "repo", "token")
+	defer server.Close()
+	client := github.NewClient(server.URL, "token", "owner", "repo")
+
+	existing, err := client.CreatePullRequest("a", "main", "Opened by hand", "", false)
+	if err != nil {
+		t.Fatalf("Failed to create pull request: %v", err)
+	}
+
+	cfg := newStackConfig(map[string]string{"a": "main"})
+	pull, created, err := submitPullRequest(git.NewFake(), client, cfg, "a", false)
+	if err != nil {
+		t.Fatalf("submitPullRequest returned error: %v", err)
+	}
+	if created || pull.Number != existing.Number {
+		t.Errorf("Expected to reuse PR #%d, got #%d (created: %v)", existing.Number, pull.Number, created)
+	}
+}
+
+func TestPushBranchMetadataOnlyWithRefStorage(t *testing.T) {
+	fake := git.NewFake()
+	cfg := newStackConfig(map[string]string{"feature": "main"})
+
+	if err := pushBranchMetadata(fake, cfg, "feature"); err != nil {
+		t.Fatalf("pushBranchMetadata returned error: %v", err)
+	}
+	if len(fake.Calls) != 0 {
+		t.Errorf("Expected no git calls with file storage, got %v", fake.Calls)
+	}
+
+	cfg.Storage = config.StorageRefs
+	if err := pushBranchMetadata(fake, cfg, "feature"); err != nil {
+		t.Fatalf("pushBranchMetadata returned error: %v", err)
+	}
+	if !fake.Called("push --force origin refs/gt-meta/feature:refs/gt-meta/feature") {
+		t.Errorf("Expected metadata ref to be pushed, got %v", fake.Calls)
+	}
+}
@@ -1,121 +1,89 @@
 package commands
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
 )
 
 var syncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "Update trunk and rebase tracked branches",
-	Long:  `Updates trunk branch from origin, rebases local tracked branches onto trunk again. If a local tracked branch no longer exists on origin, prompts for confirmation (y/n) to delete the branch.`,
-	RunE:  runSync,
+	Long:  `Updates trunk branch from origin, then restacks local tracked branches onto their parents. Tracked branches whose changes are already in trunk, including squash and rebase merges, are offered for deletion (y/n); the children of a deleted branch are moved onto its parent. If a rebase stops on a conflict, resolve it and run 'gt continue', or run 'gt abort' to roll back.`,
+	RunE:  recordOperation(runSync),
 }
 
-func runSync(cmd *cobra.Command, args []string) error {
+func runSync(g git.Git, cmd *cobra.Command, args []string) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	if err := ensureNoOperationInProgress(); err != nil {
+		return err
+	}
+
 	// Save the current branch to return to later
-	currentBranch, err := getCurrentBranch()
+	currentBranch, err := getCurrentBranch(g)
 	if err != nil {
 		return err
 	}
 
 	// Step 1: Fetch from origin to update remote tracking branches
 	fmt.Println("Fetching from origin...")
-	if err := fetchFromOrigin(); err != nil {
+	if err := fetchFromOrigin(g); err != nil {
 		return err
 	}
 
 	// Step 2: Update the trunk branch from origin
 	fmt.Printf("Updating %s from origin...\n", cfg.TrunkBranch)
-	if err := updateTrunkBranch(cfg.TrunkBranch); err != nil {
+	if err := updateTrunkBranch(g, cfg.TrunkBranch); err != nil {
 		return err
 	}
 
-	// Step 3: Process managed branches
-	branchesToDelete := []string{}
-	branchesToRebase := []string{}
+	// Step 3: Find managed branches whose changes are already in trunk, which
+	// catches squash and rebase merges as well as regular ones
+	order, err := topoOrder(cfg)
+	if err != nil {
+		return err
+	}
 
-	for branchName := range cfg.ManagedBranches {
-		// Check if branch exists locally
-		localExists, remoteExists, err := branchExists(branchName)
-		if err != nil {
-			// If we can't check remote (e.g., network issue), skip delete check
-			fmt.Printf("Warning: Could not check remote for branch '%s': %v\n", branchName, err)
-			if localExists {
-				branchesToRebase = append(branchesToRebase, branchName)
-			}
+	mergedBranches := []string{}
+	for _, branchName := range order {
+		if _, err := revParse(g, branchName); err != nil {
 			continue
 		}
 
-		if !localExists {
-			// Branch doesn't exist locally, skip it
+		merged, err := isMergedIntoTrunk(g, cfg, branchName)
+		if err != nil {
+			fmt.Printf("Warning: Could not check whether '%s' has been merged: %v\n", branchName, err)
 			continue
 		}
-
-		if !remoteExists {
-			// Branch exists locally but not on origin
-			branchesToDelete = append(branchesToDelete, branchName)
-		} else {
-			branchesToRebase = append(branchesToRebase, branchName)
+		if merged {
+			mergedBranches = append(mergedBranches, branchName)
 		}
 	}
 
-	// Step 4: Prompt for deletion of branches that don't exist on origin
-	reader := bufio.NewReader(os.Stdin)
-	for _, branchName := range branchesToDelete {
-		fmt.Printf("Branch '%s' no longer exists on origin. Delete local branch? (y/n): ", branchName)
-		response, err := reader.ReadString('\n')
+	// Step 4: Prompt for deletion of merged branches. Their children are moved
+	// onto the merged branch's parent first, so the restack below replays only
+	// the children's own commits.
+	for _, branchName := range mergedBranches {
+		confirmed, err := promptConfirm(fmt.Sprintf("Branch '%s' has been merged into %s. Delete local branch?", branchName, cfg.TrunkBranch))
 		if err != nil {
-			return fmt.Errorf("failed to read input: %w", err)
-		}
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response == "y" || response == "yes" {
-			if err := deleteBranch(branchName, cfg.TrunkBranch); err != nil {
-				fmt.Printf("Warning: Failed to delete branch '%s': %v\n", branchName, err)
-			} else {
-				// Remove from managed branches and save config immediately
-				delete(cfg.ManagedBranches, branchName)
-				if err := cfg.Save(); err != nil {
-					fmt.Printf("Warning: Failed to save config after deleting '%s': %v\n", branchName, err)
-				}
-				fmt.Printf("Deleted branch '%s'\n", branchName)
-			}
-		} else {
-			// Still rebase the branch since user wants to keep it
-			branchesToRebase = append(branchesToRebase, branchName)
+			return err
 		}
-	}
-
-	// Step 5: Rebase managed branches onto trunk
-	for _, branchName := range branchesToRebase {
-		fmt.Printf("Rebasing '%s' onto %s...\n", branchName, cfg.TrunkBranch)
-		if err := rebaseBranchOntoTrunk(branchName, cfg.TrunkBranch); err != nil {
-			fmt.Printf("Warning: Failed to rebase branch '%s': %v\n", branchName, err)
+		if !confirmed {
+			continue
 		}
-	}
 
-	// Step 6: Return to the original branch (if it still exists)
-	localExists, _, _ := branchExists(currentBranch)
-	if localExists {
-		if err := checkoutBranch(currentBranch); err != nil {
-			fmt.Printf("Warning: Failed to return to branch '%s': %v\n", currentBranch, err)
-		}
-	} else {
-		// If original branch was deleted, checkout trunk
-		if err := checkoutBranch(cfg.TrunkBranch); err != nil {
-			fmt.Printf("Warning: Failed to checkout trunk branch '%s': %v\n", cfg.TrunkBranch, err)
+		if err := deleteMergedBranch(g, cfg, branchName); err != nil {
+			fmt.Printf("Warning: Failed to delete branch '%s': %v\n", branchName, err)
+			continue
 		}
+		fmt.Printf("Deleted branch '%s'\n", branchName)
 	}
 
 	// Save updated config (in case branches were deleted)
@@ -123,85 +91,184 @@ func runSync(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
+	// Step 5: Restack managed branches onto their parents, then return to the
+	// original branch (or trunk if it was deleted)
+	returnTo := cfg.TrunkBranch
+	if localExists, _, _ := branchExists(g, currentBranch); localExists {
+		returnTo = currentBranch
+	}
+
+	order, err = topoOrder(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := startRestack(g, cfg, "sync", order, returnTo); err != nil {
+		return err
+	}
+
 	fmt.Println("Sync complete!")
 	return nil
 }
 
 // fetchFromOrigin fetches updates from the origin remote
-func fetchFromOrigin() error {
-	cmd := exec.Command("git", "fetch", "origin")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to fetch from origin: %w\nOutput: %s", err, string(output))
+func fetchFromOrigin(g git.Git) error {
+	if _, err := g.Run("fetch", "origin"); err != nil {
+		return fmt.Errorf("failed to fetch from origin: %w", err)
 	}
 	return nil
 }
 
 // updateTrunkBranch updates the trunk branch from origin
-func updateTrunkBranch(trunkBranch string) error {
+func updateTrunkBranch(g git.Git, trunkBranch string) error {
 	// First checkout the trunk branch
-	if err := checkoutBranch(trunkBranch); err != nil {
+	if err := checkoutBranch(g, trunkBranch); err != nil {
 		return err
 	}
 
 	// Pull latest changes (fast-forward only to avoid merge commits)
-	cmd := exec.Command("git", "pull", "--ff-only", "origin", trunkBranch)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to update trunk branch: %w\nOutput: %s", err, string(output))
+	if _, err := g.Run("pull", "--ff-only", "origin", trunkBranch); err != nil {
+		return fmt.Errorf("failed to update trunk branch: %w", err)
 	}
 	return nil
 }
 
-// checkoutBranch checks out the specified branch
-func checkoutBranch(branchName string) error {
-	cmd := exec.Command("git", "checkout", branchName)
-	output, err := cmd.CombinedOutput()
+// isMergedIntoTrunk reports whether all of a branch's own commits are already
+// in trunk: merged normally, rebased commit by commit, or squashed into one
+func isMergedIntoTrunk(g git.Git, cfg *config.Config, branchName string) (bool, error) {
+	trunk := cfg.TrunkBranch
+
+	base, err := branchBase(g, cfg, branchName)
 	if err != nil {
-		return fmt.Errorf("failed to checkout branch '%s': %w\nOutput: %s", branchName, err, string(output))
+		return false, err
 	}
-	return nil
+	commits, err := g.Run("rev-list", base+".."+branchName)
+	if err != nil {
+		return false, fmt.Errorf("failed to list commits of '%s': %w", branchName, err)
+	}
+	if commits == "" {
+		// A branch without commits of its own has nothing to merge
+		return false, nil
+	}
+
+	// Merged with a merge commit or fast-forwarded
+	if isAncestor(g, branchName, trunk) {
+		return true, nil
+	}
+
+	// Rebase-merged: git cherry marks commits with an equivalent patch upstream with '-'
+	if cherry, err := g.Run("cherry", trunk, branchName, base); err == nil && cherry != "" {
+		if !strings.Contains("\n"+cherry, "\n+") {
+			return true, nil
+		}
+	}
+
+	// Squash-merged: the branch's combined diff matches a single commit on trunk
+	if squashed, err := hasSquashedCommit(g, trunk, branchName, base); err == nil && squashed {
+		return true, nil
+	}
+
+	// Otherwise, compare trees: merging the branch into trunk changes nothing if
+	// trunk already has its changes, even if they landed in a different shape
+	mergedTree, err := g.Run("merge-tree", "--write-tree", trunk, branchName)
+	if err != nil {
+		return false, nil
+	}
+	trunkTree, err := g.Run("rev-parse", trunk+"^{tree}")
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve tree of '%s': %w", trunk, err)
+	}
+	return firstLine(mergedTree) == trunkTree, nil
 }
 
-// deleteBranch deletes a local branch
-func deleteBranch(branchName, trunkBranch string) error {
-	// First checkout trunk to avoid deleting the branch we're on
-	if err := checkoutBranch(trunkBranch); err != nil {
-		return err
+// hasSquashedCommit reports whether a commit on trunk since the branch forked
+// has the same patch-id as the branch's combined diff from base
+func hasSquashedCommit(g git.Git, trunk, branchName, base string) (bool, error) {
+	diff, err := g.Run("diff", "--no-color", base, branchName)
+	if err != nil {
+		return false, err
+	}
+	branchIDs, err := patchIDs(g, diff)
+	if err != nil || len(branchIDs) == 0 {
+		return false, err
 	}
 
-	// Try safe delete first (-d), which fails if branch has unmerged commits
-	cmd := exec.Command("git", "branch", "-d", branchName)
-	output, err := cmd.CombinedOutput()
+	forkPoint, err := mergeBase(g, trunk, branchName)
 	if err != nil {
-		// If safe delete fails, try force delete (-D)
-		fmt.Printf("Note: Branch '%s' has unmerged commits, force deleting...\n", branchName)
-		cmd = exec.Command("git", "branch", "-D", branchName)
-		output, err = cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("failed to delete branch '%s': %w\nOutput: %s", branchName, err, string(output))
+		return false, err
+	}
+	trunkLog, err := g.Run("log", "-p", "--no-color", forkPoint+".."+trunk)
+	if err != nil {
+		return false, err
+	}
+	trunkIDs, err := patchIDs(g, trunkLog)
+	if err != nil {
+		return false, err
+	}
+
+	for _, id := range trunkIDs {
+		if id == branchIDs[0] {
+			return true, nil
 		}
 	}
+	return false, nil
+}
+
+// patchIDs returns the stable patch-id of each patch in the given diff or log output
+func patchIDs(g git.Git, patches string) ([]string, error) {
+	if patches == "" {
+		return nil, nil
+	}
+	output, err := g.RunWithInput(patches+"\n", "patch-id", "--stable")
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute patch-id: %w", err)
+	}
+
+	ids := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			ids = append(ids, fields[0])
+		}
+	}
+	return ids, nil
+}
+
+// firstLine returns the first line of git output
+func firstLine(output string) string {
+	line, _, _ := strings.Cut(output, "\n")
+	return line
+}
+
+// deleteMergedBranch moves the children of a merged branch onto its parent,
+// then deletes the branch and its metadata
+func deleteMergedBranch(g git.Git, cfg *config.Config, branchName string) error {
+	if _, err := reparentChildren(g, cfg, branchName, cfg.ManagedBranches[branchName].Parent); err != nil {
+		return err
+	}
+
+	if err := deleteLocalBranch(g, branchName, cfg.TrunkBranch); err != nil {
+		return err
+	}
+
+	// Remove from managed branches and save config immediately
+	delete(cfg.ManagedBranches, branchName)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
 	return nil
 }
 
-// rebaseBranchOntoTrunk rebases a branch onto the trunk branch
-func rebaseBranchOntoTrunk(branchName, trunkBranch string) error {
-	// Checkout the branch to rebase
-	if err := checkoutBranch(branchName); err != nil {
+// deleteLocalBranch deletes a local branch whose changes are already in trunk
+func deleteLocalBranch(g git.Git, branchName, trunkBranch string) error {
+	// First checkout trunk to avoid deleting the branch we're on
+	if err := checkoutBranch(g, trunkBranch); err != nil {
 		return err
 	}
 
-	// Rebase onto trunk
-	cmd := exec.Command("git", "rebase", trunkBranch)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// Abort the rebase if it fails
-		abortCmd := exec.Command("git", "rebase", "--abort")
-		if abortErr := abortCmd.Run(); abortErr != nil {
-			fmt.Printf("Warning: Failed to abort rebase for '%s': %v\n", branchName, abortErr)
-		}
-		return fmt.Errorf("failed to rebase branch '%s' onto '%s': %w\nOutput: %s", branchName, trunkBranch, err, string(output))
+	// Squash and rebase merges are not ancestors of trunk, so a safe delete (-d)
+	// would refuse; the caller has already checked the changes are in trunk
+	if _, err := g.Run("branch", "-D", branchName); err != nil {
+		return fmt.Errorf("failed to delete branch '%s': %w", branchName, err)
 	}
 	return nil
 }
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+func newMergeCheckFake() *git.Fake {
+	g := git.NewFake()
+	g.On("rev-list base..feature", "c1\nc2")
+	g.Fail("merge-base --is-ancestor feature main")
+	g.On("cherry main feature base", "+ c1\n+ c2")
+	g.On("diff --no-color base feature", "diff --git a/f b/f")
+	g.On("merge-base main feature", "fork")
+	g.On("log -p --no-color fork..main", "commit t1\n\ndiff --git a/f b/f")
+	g.Fail("merge-tree --write-tree main feature")
+	return g
+}
+
+func TestIsMergedIntoTrunk(t *testing.T) {
+	cfg := newStackConfig(map[string]string{"feature": "main"})
+	branch := cfg.ManagedBranches["feature"]
+	branch.ParentSHA = "base"
+	cfg.ManagedBranches["feature"] = branch
+
+	tests := []struct {
+		name     string
+		setup    func(g *git.Fake)
+		expected bool
+	}{
+		{
+			name:     "unmerged",
+			setup:    func(g *git.Fake) { g.Fail("patch-id --stable") },
+			expected: false,
+		},
+		{
+			name:     "merged normally",
+			setup:    func(g *git.Fake) { g.On("merge-base --is-ancestor feature main", "") },
+			expected: true,
+		},
+		{
+			name:     "rebase-merged",
+			setup:    func(g *git.Fake) { g.On("cherry main feature base", "- c1\n- c2") },
+			expected: true,
+		},
+		{
+			name:     "squash-merged",
+			setup:    func(g *git.Fake) { g.On("patch-id --stable", "p1 0000") },
+			expected: true,
+		},
+		{
+			name: "changes already in trunk tree",
+			setup: func(g *git.Fake) {
+				g.Fail("patch-id --stable")
+				g.On("merge-tree --write-tree main feature", "trunktree")
+				g.On("rev-parse main^{tree}", "trunktree")
+			},
+			expected: true,
+		},
+		{
+			name:     "no commits of its own",
+			setup:    func(g *git.Fake) { g.On("rev-list base..feature", "") },
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newMergeCheckFake()
+			tt.setup(g)
+
+			merged, err := isMergedIntoTrunk(g, cfg, "feature")
+			if err != nil {
+				t.Fatalf("isMergedIntoTrunk returned error: %v", err)
+			}
+			if merged != tt.expected {
+				t.Errorf("isMergedIntoTrunk() = %v; want %v", merged, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDeleteMergedBranchReparentsChildren(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+		"c": "b",
+	})
+	g := git.NewFake()
+	g.On("merge-base a b", "atip")
+
+	// deleteMergedBranch saves the config, so work in a scratch repository
+	tempDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tempDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+	t.Chdir(tempDir)
+
+	if err := deleteMergedBranch(g, cfg, "a"); err != nil {
+		t.Fatalf("deleteMergedBranch returned error: %v", err)
+	}
+
+	if _, ok := cfg.ManagedBranches["a"]; ok {
+		t.Error("Expected 'a' to be removed from managed branches")
+	}
+	b := cfg.ManagedBranches["b"]
+	if b.Parent != "main" || b.ParentSHA != "atip" {
+		t.Errorf("Expected 'b' to be reparented onto main from 'atip', got %+v", b)
+	}
+	if cfg.ManagedBranches["c"].Parent != "b" {
+		t.Errorf("Expected 'c' to stay on 'b', got %+v", cfg.ManagedBranches["c"])
+	}
+	if !g.Called("branch -D a") {
+		t.Errorf("Expected 'a' to be deleted, got calls: %v", g.Calls)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package commands
+
+// makeRawTerminal is not supported on this platform; callers fall back to a numbered prompt
+func makeRawTerminal() (func(), error) {
+	return nil, errNoRawTerminal
+}
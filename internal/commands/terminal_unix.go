@@ -0,0 +1,35 @@
+//go:build unix
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// makeRawTerminal switches the terminal on stdin to raw mode without echo and
+// returns a function that restores the previous settings
+func makeRawTerminal() (func(), error) {
+	saved, err := runStty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := runStty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() {
+		runStty(saved)
+	}, nil
+}
+
+func runStty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errNoRawTerminal, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
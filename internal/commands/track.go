@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var (
+	trackParent string
+)
+
+var trackCmd = &cobra.Command{
+	Use:   "track [branch]",
+	Short: "Start managing an existing branch",
+	Long: `Start managing an existing git branch (the current branch by default) so that it takes part in restack, sync, submit and the other stack commands.
+The parent is given with --parent, or inferred as the trunk or tracked branch the branch forked from most recently, based on their merge-bases.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: recordOperation(runTrack),
+}
+
+var untrackCmd = &cobra.Command{
+	Use:   "untrack [branch]",
+	Short: "Stop managing a branch",
+	Long:  `Stop managing a branch (the current branch by default) by removing its gt metadata. The git branch itself is left untouched. Branches stacked on it must be untracked or moved first.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  recordOperation(runUntrack),
+}
+
+func runTrack(g git.Git, cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	branchName, err := branchArgOrCurrent(g, args)
+	if err != nil {
+		return err
+	}
+	if branchName == cfg.TrunkBranch {
+		return fmt.Errorf("cannot track trunk branch (%s)", cfg.TrunkBranch)
+	}
+	if _, managed := cfg.ManagedBranches[branchName]; managed {
+		return fmt.Errorf("branch '%s' is already tracked by gt", branchName)
+	}
+	if _, err := revParse(g, branchName); err != nil {
+		return fmt.Errorf("branch '%s' does not exist locally", branchName)
+	}
+
+	parentBranch := trackParent
+	if parentBranch != "" {
+		if _, managed := cfg.ManagedBranches[parentBranch]; !managed && parentBranch != cfg.TrunkBranch {
+			return fmt.Errorf("parent '%s' must be trunk or a branch tracked by gt", parentBranch)
+		}
+	} else {
+		parentBranch, err = inferParent(g, cfg, branchName)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := trackBranch(g, cfg, branchName, parentBranch); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Tracking branch '%s' on top of '%s'\n", branchName, parentBranch)
+	return nil
+}
+
+func runUntrack(g git.Git, cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	branchName, err := branchArgOrCurrent(g, args)
+	if err != nil {
+		return err
+	}
+	if _, managed := cfg.ManagedBranches[branchName]; !managed {
+		return fmt.Errorf("branch '%s' is not tracked by gt", branchName)
+	}
+	if children := childrenOf(cfg, branchName); len(children) > 0 {
+		return fmt.Errorf("branch '%s' has branches stacked on it (%s); untrack or move them first", branchName, strings.Join(children, ", "))
+	}
+
+	delete(cfg.ManagedBranches, branchName)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Stopped tracking branch '%s'\n", branchName)
+	return nil
+}
+
+// branchArgOrCurrent returns the branch named by the optional argument, or the current branch
+func branchArgOrCurrent(g git.Git, args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	currentBranch, err := getCurrentBranch(g)
+	if err != nil {
+		return "", err
+	}
+	if currentBranch == "HEAD" {
+		return "", fmt.Errorf("no branch given and HEAD is detached")
+	}
+	return currentBranch, nil
+}
+
+// trackBranch adds an existing branch to the managed branches with the given
+// parent, recording the point where it diverged from that parent
+func trackBranch(g git.Git, cfg *config.Config, branchName, parentBranch string) error {
+	parentSHA, err := mergeBase(g, parentBranch, branchName)
+	if err != nil {
+		return err
+	}
+
+	cfg.ManagedBranches[branchName] = config.Branch{
+		Name:      branchName,
+		Parent:    parentBranch,
+		ParentSHA: parentSHA,
+	}
+	return nil
+}
+
+// inferParent picks the most likely parent of an untracked branch among trunk
+// and the tracked branches: the one it forked from most recently, i.e. whose
+// merge-base with the branch leaves the fewest commits on the branch. Ties go
+// to the candidate with the fewest commits of its own past the fork point, so
+// a branch sitting directly on a tracked branch's tip is stacked on it.
+// Tracked branches that already contain the branch are built on top of it, so
+// they are never its parent.
+func inferParent(g git.Git, cfg *config.Config, branchName string) (string, error) {
+	candidates := []string{cfg.TrunkBranch}
+	for name := range cfg.ManagedBranches {
+		if name != branchName {
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates[1:])
+
+	best := ""
+	bestAhead, bestBehind := 0, 0
+	for _, candidate := range candidates {
+		// Stacking the branch on one of its own descendants would make every
+		// restack replay the two onto each other. Trunk is never restacked, so
+		// it stays a candidate even if the branch was already merged into it.
+		if candidate != cfg.TrunkBranch && isAncestor(g, branchName, candidate) {
+			continue
+		}
+
+		forkPoint, err := mergeBase(g, candidate, branchName)
+		if err != nil {
+			// Unrelated histories or a missing branch cannot be the parent
+			continue
+		}
+		ahead, err := countCommits(g, forkPoint, branchName)
+		if err != nil {
+			return "", err
+		}
+		behind, err := countCommits(g, forkPoint, candidate)
+		if err != nil {
+			return "", err
+		}
+
+		if best == "" || ahead < bestAhead || (ahead == bestAhead && behind < bestBehind) {
+			best, bestAhead, bestBehind = candidate, ahead, behind
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("could not infer a parent for '%s'; pass one with --parent", branchName)
+	}
+	return best, nil
+}
+
+// countCommits returns the number of commits in from..to
+func countCommits(g git.Git, from, to string) (int, error) {
+	output, err := g.Run("rev-list", "--count", from+".."+to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits in %s..%s: %w", from, to, err)
+	}
+	count, err := strconv.Atoi(output)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected output from rev-list --count: %q", output)
+	}
+	return count, nil
+}
+
+func init() {
+	trackCmd.Flags().StringVar(&trackParent, "parent", "", "Parent branch (inferred from merge-bases if not given)")
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+func TestInferParentPicksClosestForkPoint(t *testing.T) {
+	cfg := newStackConfig(map[string]string{
+		"a": "main",
+		"b": "a",
+	})
+
+	// new-work was branched from the tip of 'a': 'b' shares the same fork
+	// point but has commits of its own, and main forked earlier
+	g := git.NewFake()
+	g.On("merge-base main new-work", "m1")
+	g.On("merge-base a new-work", "a1")
+	g.On("merge-base b new-work", "a1")
+	g.Fail("merge-base --is-ancestor new-work a")
+	g.Fail("merge-base --is-ancestor new-work b")
+	g.On("rev-list --count m1..new-work", "3")
+	g.On("rev-list --count m1..main", "0")
+	g.On("rev-list --count a1..new-work", "1")
+	g.On("rev-list --count a1..a", "0")
+	g.On("rev-list --count a1..b", "2")
+
+	parent, err := inferParent(g, cfg, "new-work")
+	if err != nil {
+		t.Fatalf("inferParent returned error: %v", err)
+	}
+	if parent != "a" {
+		t.Errorf("inferParent() = %s; want a", parent)
+	}
+}
+
+func TestInferParentSkipsDescendants(t *testing.T) {
+	cfg := newStackConfig(map[string]string{"y": "main"})
+
+	// y was built on top of x in git before x was tracked: y contains x, so
+	// x must not be stacked on y even though it has no commits past their fork
+	g := git.NewFake()
+	g.On("merge-base main x", "m1")
+	g.On("merge-base y x", "xtip")
+	g.On("rev-list --count m1..x", "2")
+	g.On("rev-list --count m1..main", "0")
+	g.On("rev-list --count xtip..x", "0")
+	g.On("rev-list --count xtip..y", "1")
+
+	parent, err := inferParent(g, cfg, "x")
+	if err != nil {
+		t.Fatalf("inferParent returned error: %v", err)
+	}
+	if parent != "main" {
+		t.Errorf("inferParent() = %s; want main", parent)
+	}
+	if !g.Called("merge-base --is-ancestor x y") {
+		t.Errorf("Expected 'y' to be checked for containing 'x', got calls: %v", g.Calls)
+	}
+}
+
+func TestInferParentSkipsUnrelatedBranches(t *testing.T) {
+	cfg := newStackConfig(map[string]string{"orphan": "main"})
+
+	g := git.NewFake()
+	g.On("merge-base main new-work", "m1")
+	g.Fail("merge-base orphan new-work")
+	g.Fail("merge-base --is-ancestor new-work orphan")
+	g.On("rev-list --count m1..new-work", "2")
+	g.On("rev-list --count m1..main", "5")
+
+	parent, err := inferParent(g, cfg, "new-work")
+	if err != nil {
+		t.Fatalf("inferParent returned error: %v", err)
+	}
+	if parent != "main" {
+		t.Errorf("inferParent() = %s; want main", parent)
+	}
+}
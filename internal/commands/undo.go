@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/th1nkful/cli-gt/internal/config"
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [id]",
+	Short: "Undo gt operations",
+	Long:  `Undo the most recent gt operation, or every operation back to and including the given oplog entry. All affected branches are moved back in a single atomic ref transaction and the branch metadata is restored. The undo itself is recorded, so it can be undone as well. Requires a clean working tree.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  recordOperation(runUndo),
+}
+
+func runUndo(g git.Git, cmd *cobra.Command, args []string) error {
+	if err := ensureNoOperationInProgress(); err != nil {
+		return err
+	}
+
+	entries, err := config.LoadOpLog()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("nothing to undo")
+	}
+
+	// Find the oldest entry to undo
+	first := len(entries) - 1
+	if len(args) > 0 {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid oplog id '%s'", args[0])
+		}
+		first = -1
+		for i, entry := range entries {
+			if entry.ID == id {
+				first = i
+				break
+			}
+		}
+		if first < 0 {
+			return fmt.Errorf("no oplog entry with id %d", id)
+		}
+	}
+
+	clean, err := isWorkingTreeClean(g)
+	if err != nil {
+		return err
+	}
+	if !clean {
+		return fmt.Errorf("working tree has uncommitted changes; commit or stash them before undoing")
+	}
+
+	// Walk back from the newest entry so older "before" states win
+	target := make(map[string]string)
+	for i := len(entries) - 1; i >= first; i-- {
+		for _, change := range entries[i].Branches {
+			target[change.Name] = change.Before
+		}
+	}
+
+	current, err := listBranchSHAs(g)
+	if err != nil {
+		return err
+	}
+
+	// Detach first so that the checked out branch can be moved or deleted as well
+	if err := detachHead(g); err != nil {
+		return err
+	}
+
+	if err := setBranchSHAs(g, target, current); err != nil {
+		return err
+	}
+
+	restored := entries[first]
+	if restored.ConfigBefore != nil {
+		if err := restored.ConfigBefore.Save(); err != nil {
+			return fmt.Errorf("failed to restore config: %w", err)
+		}
+	}
+
+	if restored.HeadBefore != "" && restored.HeadBefore != "HEAD" {
+		if err := checkoutBranch(g, restored.HeadBefore); err != nil {
+			return err
+		}
+	}
+
+	for i := len(entries) - 1; i >= first; i-- {
+		fmt.Printf("Undid #%d: gt %s\n", entries[i].ID, entries[i].Command)
+	}
+	return nil
+}
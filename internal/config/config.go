@@ -1,7 +1,6 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,8 +9,13 @@ import (
 
 // Config represents the workspace configuration
 type Config struct {
-	TrunkBranch    string            `json:"trunk_branch"`
+	// SchemaVersion is the layout version of the config file, see CurrentSchemaVersion
+	SchemaVersion   int               `json:"schema_version"`
+	TrunkBranch     string            `json:"trunk_branch"`
 	ManagedBranches map[string]Branch `json:"managed_branches"`
+	// Storage selects where ManagedBranches are kept: StorageFile (the default)
+	// or StorageRefs
+	Storage string `json:"storage,omitempty"`
 }
 
 // Branch represents a managed branch
@@ -19,79 +23,97 @@ type Branch struct {
 	Name        string `json:"name"`
 	Parent      string `json:"parent"`
 	Description string `json:"description"`
+	// ParentSHA is the commit of Parent that the branch was last based on.
+	// Restacking replays the commits after it onto the parent's current tip.
+	ParentSHA string `json:"parent_sha,omitempty"`
+	// PRNumber and PRURL identify the pull request opened for the branch by 'gt submit'
+	PRNumber int    `json:"pr_number,omitempty"`
+	PRURL    string `json:"pr_url,omitempty"`
 }
 
 const (
 	configDirName  = "gt"
 	configFileName = "config.json"
+	backupSuffix   = ".bak"
 )
 
-// Load loads the configuration from the git workspace
+// Load loads the configuration from the git workspace using the storage
+// backend it is configured for
 func Load() (*Config, error) {
-	configPath, err := getConfigPath()
+	store, err := openStore()
 	if err != nil {
 		return nil, err
 	}
+	return store.Load()
+}
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		// Return default config if file doesn't exist
-		return &Config{
-			TrunkBranch:     "main",
-			ManagedBranches: make(map[string]Branch),
-		}, nil
+// Save saves the configuration to the git workspace using the storage backend
+// selected by its Storage field. Callers doing a load-modify-save should hold Lock.
+func (c *Config) Save() error {
+	store, err := storeFor(c)
+	if err != nil {
+		return err
 	}
+	return store.Save(c)
+}
 
-	data, err := os.ReadFile(configPath)
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it over path once it has been flushed to disk
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return err
 	}
+	tmpPath := tmp.Name()
+	// Clean up the temp file on any failure; after the rename this is a no-op
+	defer os.Remove(tmpPath)
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
 	}
-
-	if cfg.ManagedBranches == nil {
-		cfg.ManagedBranches = make(map[string]Branch)
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
 	}
-
-	return &cfg, nil
-}
-
-// Save saves the configuration to the git workspace
-func (c *Config) Save() error {
-	configPath, err := getConfigPath()
-	if err != nil {
+	if err := tmp.Close(); err != nil {
 		return err
 	}
-
-	// Ensure the config directory exists
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return err
 	}
 
-	data, err := json.MarshalIndent(c, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
+	return os.Rename(tmpPath, path)
+}
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+// Clone returns a deep copy of the configuration
+func (c *Config) Clone() *Config {
+	clone := &Config{
+		SchemaVersion:   c.SchemaVersion,
+		TrunkBranch:     c.TrunkBranch,
+		ManagedBranches: make(map[string]Branch, len(c.ManagedBranches)),
+		Storage:         c.Storage,
 	}
-
-	return nil
+	for name, branch := range c.ManagedBranches {
+		clone.ManagedBranches[name] = branch
+	}
+	return clone
 }
 
 // getConfigPath returns the path to the config file in the git workspace
 // Config is stored inside .git/gt/ directory to keep it invisible and device-specific
 func getConfigPath() (string, error) {
+	return getStatePath(configFileName)
+}
+
+// getStatePath returns the path to a file inside the .git/gt/ directory
+func getStatePath(fileName string) (string, error) {
 	gitDir, err := findGitDir()
 	if err != nil {
 		return "", err
 	}
 
-	return filepath.Join(gitDir, configDirName, configFileName), nil
+	return filepath.Join(gitDir, configDirName, fileName), nil
 }
 
 // findGitDir finds the .git directory of the git repository
@@ -158,3 +158,83 @@ func TestFindGitDir(t *testing.T) {
 		t.Errorf("Expected git dir '%s', got '%s'", gitDir, foundGitDir)
 	}
 }
+
+func TestLoadRecoversCorruptConfigFromBackup(t *testing.T) {
+	// Create a temporary directory to act as git root
+	tempDir, err := os.MkdirTemp("", "gt-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Create .git directory to simulate git repo
+	gitDir := filepath.Join(tempDir, ".git")
+	if err := os.Mkdir(gitDir, 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+
+	// Change to temp directory
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current dir: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	cfg := &Config{
+		TrunkBranch: "develop",
+		ManagedBranches: map[string]Branch{
+			"feature-1": {Name: "feature-1", Parent: "develop"},
+		},
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	// The save must not leave temp files behind
+	entries, err := os.ReadDir(filepath.Join(gitDir, "gt"))
+	if err != nil {
+		t.Fatalf("Failed to read config dir: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != "config.json" && entry.Name() != "config.json.bak" {
+			t.Errorf("Unexpected file left in config dir: %s", entry.Name())
+		}
+	}
+
+	// Simulate a truncated write
+	configPath := filepath.Join(gitDir, "gt", "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"trunk_branch": "dev`), 0644); err != nil {
+		t.Fatalf("Failed to corrupt config: %v", err)
+	}
+
+	loadedCfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected Load to recover from backup, got error: %v", err)
+	}
+	if loadedCfg.TrunkBranch != "develop" {
+		t.Errorf("Expected trunk branch 'develop', got '%s'", loadedCfg.TrunkBranch)
+	}
+	if _, ok := loadedCfg.ManagedBranches["feature-1"]; !ok {
+		t.Error("Expected to find 'feature-1' branch")
+	}
+
+	// The config file itself should have been repaired
+	if err := os.Remove(configPath + ".bak"); err != nil {
+		t.Fatalf("Failed to remove backup: %v", err)
+	}
+	if _, err := Load(); err != nil {
+		t.Errorf("Expected repaired config to load without backup, got error: %v", err)
+	}
+
+	// With both copies corrupt, Load must fail rather than return an empty config
+	if err := os.WriteFile(configPath, []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to corrupt config: %v", err)
+	}
+	if _, err := Load(); err == nil {
+		t.Error("Expected Load to fail for a corrupt config without a backup")
+	}
+}
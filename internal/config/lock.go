@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const lockFileName = "config.lock"
+
+// lockTimeout is how long Lock waits for another gt process to release the lock
+var lockTimeout = 10 * time.Second
+
+// Lock acquires the advisory workspace lock that serialises gt processes
+// across a load-modify-save of the config and other state in .git/gt/.
+// It waits up to lockTimeout and returns a function that releases the lock.
+func Lock() (func(), error) {
+	lockPath, err := getStatePath(lockFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		unlock, acquired, err := tryLock(lockPath)
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			return unlock, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for another gt process to release %s", lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !unix
+
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// tryLock creates the lock file exclusively. Unlike flock the file is left
+// behind if the process dies, in which case it has to be removed by hand.
+func tryLock(lockPath string) (func(), bool, error) {
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create lock file: %w", err)
+	}
+	file.Close()
+
+	return func() {
+		os.Remove(lockPath)
+	}, true, nil
+}
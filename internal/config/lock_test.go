@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockIsExclusive(t *testing.T) {
+	// Create a temporary directory to act as git root
+	tempDir, err := os.MkdirTemp("", "gt-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Create .git directory to simulate git repo
+	if err := os.Mkdir(filepath.Join(tempDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+
+	// Change to temp directory
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current dir: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	originalTimeout := lockTimeout
+	lockTimeout = 200 * time.Millisecond
+	defer func() { lockTimeout = originalTimeout }()
+
+	unlock, err := Lock()
+	if err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+
+	if _, err := Lock(); err == nil {
+		t.Fatal("Expected second Lock to time out while the first is held")
+	}
+
+	unlock()
+
+	unlock, err = Lock()
+	if err != nil {
+		t.Fatalf("Failed to acquire lock after release: %v", err)
+	}
+	unlock()
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CurrentSchemaVersion is the version of the config file layout written by this gt.
+// Bump it together with a new entry in migrations whenever the layout changes.
+const CurrentSchemaVersion = 2
+
+// legacySchemaVersion is assumed for config files written before schema_version existed
+const legacySchemaVersion = 1
+
+// migration upgrades a decoded config file by one schema version in place
+type migration func(raw map[string]interface{}) error
+
+// migrations maps each schema version to the step that upgrades a file from it
+// to the next version. Load applies them in order up to CurrentSchemaVersion.
+var migrations = map[int]migration{
+	1: migrateV1ToV2,
+}
+
+// NewerSchemaError is returned when a config file was written by a newer gt
+// using a schema version this gt does not understand
+type NewerSchemaError struct {
+	Version int
+}
+
+func (e *NewerSchemaError) Error() string {
+	return fmt.Sprintf("config file uses schema version %d but this gt only supports up to version %d; upgrade gt to use this workspace", e.Version, CurrentSchemaVersion)
+}
+
+// migrateConfig upgrades raw config file contents to CurrentSchemaVersion
+func migrateConfig(data []byte) ([]byte, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("config file is empty")
+	}
+
+	version := legacySchemaVersion
+	if value, ok := raw["schema_version"]; ok {
+		number, ok := value.(float64)
+		if !ok || number != float64(int(number)) || number < 1 {
+			return nil, fmt.Errorf("invalid schema_version %v", value)
+		}
+		version = int(number)
+	}
+
+	if version > CurrentSchemaVersion {
+		return nil, &NewerSchemaError{Version: version}
+	}
+	if version == CurrentSchemaVersion {
+		return data, nil
+	}
+
+	for ; version < CurrentSchemaVersion; version++ {
+		migrate, ok := migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("no migration from config schema version %d", version)
+		}
+		if err := migrate(raw); err != nil {
+			return nil, fmt.Errorf("failed to migrate config from schema version %d: %w", version, err)
+		}
+		raw["schema_version"] = version + 1
+	}
+
+	return json.Marshal(raw)
+}
+
+// migrateV1ToV2 is the only change in schema version 2: every managed branch
+// gets a name, taken from its map key when the file does not have one (for
+// example a config file written or edited by hand). Names already recorded
+// are kept.
+func migrateV1ToV2(raw map[string]interface{}) error {
+	branches, ok := raw["managed_branches"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for key, value := range branches {
+		branch, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("managed branch '%s' is not an object", key)
+		}
+		if name, _ := branch["name"].(string); name == "" {
+			branch["name"] = key
+		}
+	}
+	return nil
+}
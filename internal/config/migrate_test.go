@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrationsCoverEveryVersion(t *testing.T) {
+	for version := legacySchemaVersion; version < CurrentSchemaVersion; version++ {
+		if _, ok := migrations[version]; !ok {
+			t.Errorf("Missing migration from schema version %d", version)
+		}
+	}
+}
+
+func TestMigrateV1ToV2FillsMissingNames(t *testing.T) {
+	raw := map[string]interface{}{
+		"managed_branches": map[string]interface{}{
+			"unnamed": map[string]interface{}{"parent": "main"},
+			"named":   map[string]interface{}{"name": "named", "parent": "unnamed"},
+		},
+	}
+	if err := migrateV1ToV2(raw); err != nil {
+		t.Fatalf("migrateV1ToV2 returned error: %v", err)
+	}
+
+	branches := raw["managed_branches"].(map[string]interface{})
+	if name := branches["unnamed"].(map[string]interface{})["name"]; name != "unnamed" {
+		t.Errorf("Expected the missing name to be filled from the key, got %v", name)
+	}
+	if name := branches["named"].(map[string]interface{})["name"]; name != "named" {
+		t.Errorf("Expected the recorded name to be kept, got %v", name)
+	}
+}
+
+func TestLoadMigratesLegacyConfig(t *testing.T) {
+	// Create a temporary directory to act as git root
+	tempDir, err := os.MkdirTemp("", "gt-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Create .git/gt directory to simulate git repo with existing config
+	configDir := filepath.Join(tempDir, ".git", "gt")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+
+	// Change to temp directory
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current dir: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	// A version 1 file, written before schema_version existed, whose branch
+	// has no name recorded
+	legacy := `{
+  "trunk_branch": "develop",
+  "managed_branches": {
+    "feature-1": {"parent": "develop", "description": "Feature 1"}
+  }
+}`
+	configPath := filepath.Join(configDir, "config.json")
+	if err := os.WriteFile(configPath, []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write legacy config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load legacy config: %v", err)
+	}
+
+	if cfg.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", CurrentSchemaVersion, cfg.SchemaVersion)
+	}
+	if cfg.TrunkBranch != "develop" {
+		t.Errorf("Expected trunk branch 'develop', got '%s'", cfg.TrunkBranch)
+	}
+	branch := cfg.ManagedBranches["feature-1"]
+	if branch.Name != "feature-1" || branch.Parent != "develop" || branch.Description != "Feature 1" {
+		t.Errorf("Unexpected migrated branch: %+v", branch)
+	}
+}
+
+func TestLoadRefusesNewerSchema(t *testing.T) {
+	// Create a temporary directory to act as git root
+	tempDir, err := os.MkdirTemp("", "gt-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Create .git directory to simulate git repo
+	if err := os.Mkdir(filepath.Join(tempDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+
+	// Change to temp directory
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current dir: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	// Save a valid config so that a backup exists
+	cfg := &Config{TrunkBranch: "main", ManagedBranches: map[string]Branch{}}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	configPath := filepath.Join(tempDir, ".git", "gt", "config.json")
+	newer := `{"schema_version": 999, "trunk_branch": "main", "managed_branches": {}}`
+	if err := os.WriteFile(configPath, []byte(newer), 0644); err != nil {
+		t.Fatalf("Failed to write newer config: %v", err)
+	}
+
+	_, err = Load()
+	var newerErr *NewerSchemaError
+	if !errors.As(err, &newerErr) {
+		t.Fatalf("Expected NewerSchemaError, got %v", err)
+	}
+	if newerErr.Version != 999 {
+		t.Errorf("Expected version 999 in error, got %d", newerErr.Version)
+	}
+
+	// The newer file must not be replaced by the older backup
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if string(data) != newer {
+		t.Error("Expected newer config file to be left untouched")
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const operationFileName = "operation.json"
+
+// Operation records a restack that stopped on a conflict so that it can be
+// resumed with 'gt continue' or rolled back with 'gt abort'
+type Operation struct {
+	// Command is the gt command that started the operation
+	Command string `json:"command"`
+	// OriginalBranch is the branch to check out once the operation finishes
+	OriginalBranch string `json:"original_branch"`
+	// AbortBranch is the branch to check out if the operation is aborted, when
+	// it differs from OriginalBranch
+	AbortBranch string `json:"abort_branch,omitempty"`
+	// Current is the branch whose rebase stopped on a conflict
+	Current string `json:"current"`
+	// CurrentOnto is the parent commit the current branch is being rebased onto
+	CurrentOnto string `json:"current_onto"`
+	// Remaining lists the branches still to be restacked, in order
+	Remaining []string `json:"remaining"`
+	// OriginalSHAs maps each branch in the operation to its commit before it
+	// started, or to an empty string if the operation created it
+	OriginalSHAs map[string]string `json:"original_shas"`
+	// OriginalConfig is the configuration before the operation started
+	OriginalConfig *Config `json:"original_config"`
+}
+
+// LoadOperation loads the in-progress operation, returning nil if there is none
+func LoadOperation() (*Operation, error) {
+	operationPath, err := getStatePath(operationFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(operationPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operation state: %w", err)
+	}
+
+	var op Operation
+	if err := json.Unmarshal(data, &op); err != nil {
+		return nil, fmt.Errorf("failed to parse operation state: %w", err)
+	}
+
+	return &op, nil
+}
+
+// Save persists the operation so that it can be resumed later
+func (o *Operation) Save() error {
+	operationPath, err := getStatePath(operationFileName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(operationPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal operation state: %w", err)
+	}
+
+	if err := writeFileAtomic(operationPath, data); err != nil {
+		return fmt.Errorf("failed to write operation state: %w", err)
+	}
+
+	return nil
+}
+
+// ClearOperation removes the persisted operation state, if any
+func ClearOperation() error {
+	operationPath, err := getStatePath(operationFileName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(operationPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove operation state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOperationSaveLoadAndClear(t *testing.T) {
+	// Create a temporary directory to act as git root
+	tempDir, err := os.MkdirTemp("", "gt-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Create .git directory to simulate git repo
+	if err := os.Mkdir(filepath.Join(tempDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+
+	// Change to temp directory
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current dir: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	// No operation should be in progress initially
+	op, err := LoadOperation()
+	if err != nil {
+		t.Fatalf("Failed to load operation: %v", err)
+	}
+	if op != nil {
+		t.Fatalf("Expected no operation in progress, got %+v", op)
+	}
+
+	saved := &Operation{
+		Command:        "restack",
+		OriginalBranch: "feature-2",
+		Current:        "feature-1",
+		CurrentOnto:    "abc123",
+		Remaining:      []string{"feature-2"},
+		OriginalSHAs:   map[string]string{"feature-1": "def456", "feature-2": "789abc"},
+		OriginalConfig: &Config{TrunkBranch: "main", ManagedBranches: map[string]Branch{}},
+	}
+	if err := saved.Save(); err != nil {
+		t.Fatalf("Failed to save operation: %v", err)
+	}
+
+	op, err = LoadOperation()
+	if err != nil {
+		t.Fatalf("Failed to load operation: %v", err)
+	}
+	if op == nil {
+		t.Fatal("Expected an operation in progress")
+	}
+	if op.Current != "feature-1" || op.CurrentOnto != "abc123" {
+		t.Errorf("Expected current step feature-1 onto abc123, got %s onto %s", op.Current, op.CurrentOnto)
+	}
+	if len(op.Remaining) != 1 || op.Remaining[0] != "feature-2" {
+		t.Errorf("Expected remaining [feature-2], got %v", op.Remaining)
+	}
+	if op.OriginalSHAs["feature-2"] != "789abc" {
+		t.Errorf("Expected original SHA '789abc' for feature-2, got '%s'", op.OriginalSHAs["feature-2"])
+	}
+
+	if err := ClearOperation(); err != nil {
+		t.Fatalf("Failed to clear operation: %v", err)
+	}
+	op, err = LoadOperation()
+	if err != nil {
+		t.Fatalf("Failed to load operation: %v", err)
+	}
+	if op != nil {
+		t.Error("Expected operation to be cleared")
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const opLogFileName = "oplog.jsonl"
+
+// OpLogEntry records the effect of one mutating gt command
+type OpLogEntry struct {
+	ID        int       `json:"id"`
+	Command   string    `json:"command"`
+	Timestamp time.Time `json:"timestamp"`
+	// Branches lists every local branch the command created, moved or deleted
+	Branches []BranchChange `json:"branches"`
+	// HeadBefore and HeadAfter are the checked out branch around the command
+	HeadBefore string `json:"head_before"`
+	HeadAfter  string `json:"head_after"`
+	// ConfigBefore and ConfigAfter are snapshots of the configuration around the command
+	ConfigBefore *Config `json:"config_before"`
+	ConfigAfter  *Config `json:"config_after"`
+}
+
+// BranchChange records the commit a branch pointed at before and after a
+// command. An empty SHA means the branch did not exist.
+type BranchChange struct {
+	Name   string `json:"name"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// LoadOpLog loads all operation log entries, oldest first
+func LoadOpLog() ([]OpLogEntry, error) {
+	opLogPath, err := getStatePath(opLogFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(opLogPath)
+	if os.IsNotExist(err) {
+		return []OpLogEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operation log: %w", err)
+	}
+	defer file.Close()
+
+	entries := []OpLogEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry OpLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse operation log: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read operation log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// AppendOpLog assigns the next ID to the entry and appends it to the operation log
+func AppendOpLog(entry *OpLogEntry) error {
+	entries, err := LoadOpLog()
+	if err != nil {
+		return err
+	}
+	entry.ID = 1
+	if len(entries) > 0 {
+		entry.ID = entries[len(entries)-1].ID + 1
+	}
+
+	opLogPath, err := getStatePath(opLogFileName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(opLogPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal operation log entry: %w", err)
+	}
+
+	file, err := os.OpenFile(opLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open operation log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write operation log: %w", err)
+	}
+
+	return nil
+}
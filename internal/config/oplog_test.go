@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendAndLoadOpLog(t *testing.T) {
+	// Create a temporary directory to act as git root
+	tempDir, err := os.MkdirTemp("", "gt-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Create .git directory to simulate git repo
+	if err := os.Mkdir(filepath.Join(tempDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git dir: %v", err)
+	}
+
+	// Change to temp directory
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current dir: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	entries, err := LoadOpLog()
+	if err != nil {
+		t.Fatalf("Failed to load empty operation log: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("Expected empty operation log, got %d entries", len(entries))
+	}
+
+	for _, command := range []string{"create one", "modify"} {
+		entry := &OpLogEntry{
+			Command:   command,
+			Timestamp: time.Now().UTC(),
+			Branches:  []BranchChange{{Name: "one", Before: "", After: "abc"}},
+			ConfigBefore: &Config{
+				TrunkBranch:     "main",
+				ManagedBranches: map[string]Branch{},
+			},
+		}
+		if err := AppendOpLog(entry); err != nil {
+			t.Fatalf("Failed to append to operation log: %v", err)
+		}
+	}
+
+	entries, err = LoadOpLog()
+	if err != nil {
+		t.Fatalf("Failed to load operation log: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].ID != 1 || entries[1].ID != 2 {
+		t.Errorf("Expected sequential IDs 1 and 2, got %d and %d", entries[0].ID, entries[1].ID)
+	}
+	if entries[1].Command != "modify" {
+		t.Errorf("Expected second command 'modify', got '%s'", entries[1].Command)
+	}
+	if entries[0].ConfigBefore == nil || entries[0].ConfigBefore.TrunkBranch != "main" {
+		t.Error("Expected config snapshot to be preserved")
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+// MetaRefPrefix is the ref namespace holding branch metadata for StorageRefs
+const MetaRefPrefix = "refs/gt-meta/"
+
+// MetaRef returns the ref that holds a branch's metadata
+func MetaRef(branch string) string {
+	return MetaRefPrefix + branch
+}
+
+// RefStore keeps each managed branch's metadata as a JSON blob under
+// refs/gt-meta/<branch>, so that it can be pushed and fetched like any other
+// ref. The remaining settings, such as the trunk branch, stay in the config file.
+type RefStore struct {
+	Git      git.Git
+	Settings *FileStore
+}
+
+// NewRefStore creates a RefStore that keeps its settings in the given file store
+func NewRefStore(g git.Git, settings *FileStore) *RefStore {
+	return &RefStore{Git: g, Settings: settings}
+}
+
+// Load reads the settings from the config file and the branches from their refs
+func (s *RefStore) Load() (*Config, error) {
+	cfg, err := s.Settings.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := s.LoadBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.ManagedBranches = branches
+	cfg.Storage = StorageRefs
+	return cfg, nil
+}
+
+// Save writes the branches to their refs and the remaining settings to the config file
+func (s *RefStore) Save(cfg *Config) error {
+	if err := s.SaveBranches(cfg.ManagedBranches); err != nil {
+		return err
+	}
+
+	settings := cfg.Clone()
+	settings.ManagedBranches = make(map[string]Branch)
+	settings.Storage = StorageRefs
+	if err := s.Settings.Save(settings); err != nil {
+		return err
+	}
+	cfg.SchemaVersion = settings.SchemaVersion
+	return nil
+}
+
+// LoadBranches reads the metadata of every branch that has a metadata ref
+func (s *RefStore) LoadBranches() (map[string]Branch, error) {
+	refs, err := s.listMetaRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make(map[string]Branch, len(refs))
+	for name, sha := range refs {
+		data, err := s.Git.Run("cat-file", "blob", sha)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read metadata for '%s': %w", name, err)
+		}
+
+		var branch Branch
+		if err := json.Unmarshal([]byte(data), &branch); err != nil {
+			return nil, fmt.Errorf("failed to parse metadata for '%s': %w", name, err)
+		}
+		branch.Name = name
+		branches[name] = branch
+	}
+	return branches, nil
+}
+
+// SaveBranches writes a blob for each branch and points its metadata ref at it,
+// deleting the refs of branches that are no longer managed. All refs are
+// updated in a single transaction.
+func (s *RefStore) SaveBranches(branches map[string]Branch) error {
+	existing, err := s.listMetaRefs()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(branches))
+	for name := range branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var updates strings.Builder
+	for _, name := range names {
+		data, err := json.MarshalIndent(branches[name], "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata for '%s': %w", name, err)
+		}
+
+		sha, err := s.Git.RunWithInput(string(data)+"\n", "hash-object", "-w", "--stdin")
+		if err != nil {
+			return fmt.Errorf("failed to write metadata for '%s': %w", name, err)
+		}
+		if existing[name] != sha {
+			fmt.Fprintf(&updates, "update %s %s\n", MetaRef(name), sha)
+		}
+	}
+
+	for name := range existing {
+		if _, managed := branches[name]; !managed {
+			fmt.Fprintf(&updates, "delete %s\n", MetaRef(name))
+		}
+	}
+
+	if updates.Len() == 0 {
+		return nil
+	}
+	if _, err := s.Git.RunWithInput(updates.String(), "update-ref", "--stdin"); err != nil {
+		return fmt.Errorf("failed to update metadata refs: %w", err)
+	}
+	return nil
+}
+
+// listMetaRefs returns the blob each metadata ref points at, keyed by branch name
+func (s *RefStore) listMetaRefs() (map[string]string, error) {
+	output, err := s.Git.Run("for-each-ref", "--format=%(refname) %(objectname)", MetaRefPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metadata refs: %w", err)
+	}
+
+	refs := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		refName, sha, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		refs[strings.TrimPrefix(refName, MetaRefPrefix)] = sha
+	}
+	return refs, nil
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+func TestRefStoreSaveAndLoad(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	g := git.NewExec(dir)
+	if _, err := g.Run("init", "-q", "-b", "main"); err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+
+	store := NewRefStore(g, &FileStore{Path: filepath.Join(dir, ".git", "gt", "config.json")})
+	cfg := &Config{
+		TrunkBranch: "develop",
+		ManagedBranches: map[string]Branch{
+			"feature-1":     {Name: "feature-1", Parent: "develop", Description: "Feature 1", ParentSHA: "abc123"},
+			"user/feature2": {Name: "user/feature2", Parent: "feature-1", PRNumber: 7},
+		},
+	}
+	if err := store.Save(cfg); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	if _, err := g.Run("rev-parse", "--verify", "refs/gt-meta/user/feature2"); err != nil {
+		t.Errorf("Expected metadata ref for 'user/feature2': %v", err)
+	}
+
+	// Branch metadata must not be duplicated in the settings file
+	settings, err := store.Settings.Load()
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+	if len(settings.ManagedBranches) != 0 || settings.Storage != StorageRefs {
+		t.Errorf("Unexpected settings file contents: %+v", settings)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if loaded.TrunkBranch != "develop" {
+		t.Errorf("Expected trunk branch 'develop', got '%s'", loaded.TrunkBranch)
+	}
+	if !reflect.DeepEqual(loaded.ManagedBranches, cfg.ManagedBranches) {
+		t.Errorf("Loaded branches = %+v; want %+v", loaded.ManagedBranches, cfg.ManagedBranches)
+	}
+
+	// Removing a branch deletes its ref
+	delete(cfg.ManagedBranches, "feature-1")
+	if err := store.Save(cfg); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	if _, err := g.Run("rev-parse", "--verify", "refs/gt-meta/feature-1"); err == nil {
+		t.Error("Expected metadata ref for 'feature-1' to be deleted")
+	}
+	branches, err := store.LoadBranches()
+	if err != nil {
+		t.Fatalf("Failed to load branches: %v", err)
+	}
+	if len(branches) != 1 {
+		t.Errorf("Expected 1 branch after delete, got %v", branches)
+	}
+}
+
+func TestLoadUsesMetadataRefsWithoutConfigFile(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	g := git.NewExec(dir)
+	if _, err := g.Run("init", "-q", "-b", "main"); err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+
+	// Change to the repository
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current dir: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change to repo dir: %v", err)
+	}
+
+	// Simulate metadata refs fetched into a fresh clone, with no config file
+	store := NewRefStore(g, &FileStore{Path: filepath.Join(dir, ".git", "gt", "config.json")})
+	branches := map[string]Branch{"feature-1": {Name: "feature-1", Parent: "main"}}
+	if err := store.SaveBranches(branches); err != nil {
+		t.Fatalf("Failed to save branches: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Storage != StorageRefs {
+		t.Errorf("Expected ref storage to be detected, got '%s'", cfg.Storage)
+	}
+	if _, ok := cfg.ManagedBranches["feature-1"]; !ok {
+		t.Error("Expected to find 'feature-1' branch")
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/th1nkful/cli-gt/internal/git"
+)
+
+// Storage backends for managed branch metadata, selected by Config.Storage
+const (
+	// StorageFile keeps branch metadata in .git/gt/config.json with the other settings
+	StorageFile = "file"
+	// StorageRefs keeps each branch's metadata in a blob under refs/gt-meta/<branch>
+	StorageRefs = "refs"
+)
+
+// Store reads and writes the workspace configuration
+type Store interface {
+	Load() (*Config, error)
+	Save(cfg *Config) error
+}
+
+// newGit creates the git runner used by the ref storage backend
+var newGit = func() git.Git { return git.NewExec("") }
+
+// openStore returns the store to load the workspace configuration from. Ref
+// storage is used when the config file selects it, or when there is no config
+// file yet but branch metadata refs exist, e.g. after fetching them into a
+// fresh clone.
+func openStore() (Store, error) {
+	files, err := NewFileStore()
+	if err != nil {
+		return nil, err
+	}
+
+	if !files.Exists() {
+		refs := NewRefStore(newGit(), files)
+		if branches, err := refs.LoadBranches(); err == nil && len(branches) > 0 {
+			return refs, nil
+		}
+		return files, nil
+	}
+
+	cfg, err := files.Load()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Storage == StorageRefs {
+		return NewRefStore(newGit(), files), nil
+	}
+	return files, nil
+}
+
+// storeFor returns the store selected by a configuration's Storage field
+func storeFor(cfg *Config) (Store, error) {
+	files, err := NewFileStore()
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Storage {
+	case "", StorageFile:
+		return files, nil
+	case StorageRefs:
+		return NewRefStore(newGit(), files), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend '%s'", cfg.Storage)
+	}
+}
+
+// FileStore keeps the whole configuration in a JSON file
+type FileStore struct {
+	Path string
+}
+
+// NewFileStore creates a FileStore for the workspace's .git/gt/config.json
+func NewFileStore() (*FileStore, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{Path: configPath}, nil
+}
+
+// Exists reports whether the config file has been written yet
+func (s *FileStore) Exists() bool {
+	_, err := os.Stat(s.Path)
+	return err == nil
+}
+
+// Load reads the config file, returning a default configuration if it does not
+// exist. If the file is truncated or corrupt, the backup written by the last
+// successful Save is used instead and the file is restored from it.
+func (s *FileStore) Load() (*Config, error) {
+	if !s.Exists() {
+		// Return default config if file doesn't exist
+		return &Config{
+			SchemaVersion:   CurrentSchemaVersion,
+			TrunkBranch:     "main",
+			ManagedBranches: make(map[string]Branch),
+		}, nil
+	}
+
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg, parseErr := parseConfig(data)
+	var newerErr *NewerSchemaError
+	if errors.As(parseErr, &newerErr) {
+		// The file is fine, just not ours to read; the backup would be stale
+		return nil, parseErr
+	}
+	if parseErr != nil {
+		backupPath := s.Path + backupSuffix
+		backupData, err := os.ReadFile(backupPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", parseErr)
+		}
+		cfg, err = parseConfig(backupData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w (backup is also unreadable: %v)", parseErr, err)
+		}
+
+		fmt.Fprintf(os.Stderr, "Warning: %s is corrupt (%v); restored it from %s\n", s.Path, parseErr, backupPath)
+		if err := writeFileAtomic(s.Path, backupData); err != nil {
+			return nil, fmt.Errorf("failed to restore config file from backup: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Save writes the config file. The file is replaced atomically, so readers see
+// either the old or the new config but never a partial write, and a backup
+// copy is kept for Load to recover from.
+func (s *FileStore) Save(cfg *Config) error {
+	// Ensure the config directory exists
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	cfg.SchemaVersion = CurrentSchemaVersion
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := writeFileAtomic(s.Path, data); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	if err := writeFileAtomic(s.Path+backupSuffix, data); err != nil {
+		return fmt.Errorf("failed to write config backup: %w", err)
+	}
+
+	return nil
+}
+
+// parseConfig decodes the contents of a config file, migrating it from older
+// schema versions if needed
+func parseConfig(data []byte) (*Config, error) {
+	data, err := migrateConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.ManagedBranches == nil {
+		cfg.ManagedBranches = make(map[string]Branch)
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,115 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Fake is a Git implementation for tests that records every invocation and
+// replies with canned responses keyed by the space-joined arguments
+type Fake struct {
+	// Calls holds the arguments of every invocation, in order
+	Calls [][]string
+	// Inputs holds the standard input passed to RunWithInput, keyed by call index
+	Inputs map[int]string
+	// Envs holds the environment added through WithEnv, keyed by call index
+	Envs map[int][]string
+	// Responses maps space-joined arguments to the result to return.
+	// Unknown invocations succeed with empty output.
+	Responses map[string]Response
+}
+
+// Response is a canned result for a Fake invocation
+type Response struct {
+	Output string
+	Err    error
+}
+
+// NewFake creates an empty Fake
+func NewFake() *Fake {
+	return &Fake{
+		Inputs:    make(map[int]string),
+		Envs:      make(map[int][]string),
+		Responses: make(map[string]Response),
+	}
+}
+
+// On registers the output returned when git is run with the given space-joined arguments
+func (f *Fake) On(args string, output string) {
+	f.Responses[args] = Response{Output: output}
+}
+
+// Fail registers a failure for the given space-joined arguments
+func (f *Fake) Fail(args string) {
+	f.Responses[args] = Response{Err: &Error{Args: strings.Fields(args), Err: fmt.Errorf("exit status 1")}}
+}
+
+// Run records the invocation and returns the canned response
+func (f *Fake) Run(args ...string) (string, error) {
+	f.Calls = append(f.Calls, args)
+	response := f.Responses[strings.Join(args, " ")]
+	return response.Output, response.Err
+}
+
+// RunWithInput records the invocation and its input and returns the canned response
+func (f *Fake) RunWithInput(input string, args ...string) (string, error) {
+	f.Inputs[len(f.Calls)] = input
+	return f.Run(args...)
+}
+
+// RunRaw records the invocation and returns the canned response
+func (f *Fake) RunRaw(args ...string) (string, error) {
+	return f.Run(args...)
+}
+
+// RunInteractive records the invocation and returns the canned error, if any
+func (f *Fake) RunInteractive(args ...string) error {
+	_, err := f.Run(args...)
+	return err
+}
+
+// WithEnv returns a Git that records its invocations, and the given
+// environment, on f
+func (f *Fake) WithEnv(env ...string) Git {
+	return &fakeWithEnv{fake: f, env: env}
+}
+
+// fakeWithEnv is the Git returned by Fake.WithEnv
+type fakeWithEnv struct {
+	fake *Fake
+	env  []string
+}
+
+func (e *fakeWithEnv) Run(args ...string) (string, error) {
+	e.fake.Envs[len(e.fake.Calls)] = e.env
+	return e.fake.Run(args...)
+}
+
+func (e *fakeWithEnv) RunWithInput(input string, args ...string) (string, error) {
+	e.fake.Envs[len(e.fake.Calls)] = e.env
+	return e.fake.RunWithInput(input, args...)
+}
+
+func (e *fakeWithEnv) RunRaw(args ...string) (string, error) {
+	e.fake.Envs[len(e.fake.Calls)] = e.env
+	return e.fake.RunRaw(args...)
+}
+
+func (e *fakeWithEnv) RunInteractive(args ...string) error {
+	e.fake.Envs[len(e.fake.Calls)] = e.env
+	return e.fake.RunInteractive(args...)
+}
+
+func (e *fakeWithEnv) WithEnv(env ...string) Git {
+	return &fakeWithEnv{fake: e.fake, env: append(append([]string{}, e.env...), env...)}
+}
+
+// Called reports whether git was run with the given space-joined arguments
+func (f *Fake) Called(args string) bool {
+	for _, call := range f.Calls {
+		if strings.Join(call, " ") == args {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Git runs git commands
+type Git interface {
+	// Run executes git with the given arguments and returns its trimmed standard output
+	Run(args ...string) (string, error)
+	// RunWithInput is like Run but feeds input to git's standard input
+	RunWithInput(input string, args ...string) (string, error)
+	// RunRaw is like Run but returns standard output exactly as git wrote it,
+	// for output such as patches where trailing whitespace matters
+	RunRaw(args ...string) (string, error)
+	// RunInteractive executes git attached to the terminal, for commands that prompt the user
+	RunInteractive(args ...string) error
+	// WithEnv returns a Git whose invocations also get the given environment
+	// variables, in KEY=value form
+	WithEnv(env ...string) Git
+}
+
+// Error is returned when a git command exits unsuccessfully
+type Error struct {
+	Args   []string
+	Output string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Output == "" {
+		return fmt.Sprintf("git %s: %v", strings.Join(e.Args, " "), e.Err)
+	}
+	return fmt.Sprintf("git %s: %v\nOutput: %s", strings.Join(e.Args, " "), e.Err, e.Output)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Exec runs git as a subprocess
+type Exec struct {
+	// Dir is the working directory git runs in; empty means the current directory
+	Dir string
+	// Trace, if set, receives every invocation along with how long it took
+	Trace io.Writer
+	// Env holds extra environment variables, in KEY=value form, that git runs with
+	Env []string
+}
+
+// NewExec creates an exec-backed Git that runs in dir. Invocations are traced
+// to stderr when the GT_TRACE environment variable is set.
+func NewExec(dir string) *Exec {
+	g := &Exec{Dir: dir}
+	if os.Getenv("GT_TRACE") != "" {
+		g.Trace = os.Stderr
+	}
+	return g
+}
+
+// Run executes git with the given arguments and returns its trimmed standard output
+func (g *Exec) Run(args ...string) (string, error) {
+	output, err := g.run(nil, args)
+	return strings.TrimSpace(output), err
+}
+
+// RunWithInput is like Run but feeds input to git's standard input
+func (g *Exec) RunWithInput(input string, args ...string) (string, error) {
+	output, err := g.run(strings.NewReader(input), args)
+	return strings.TrimSpace(output), err
+}
+
+// RunRaw is like Run but returns standard output exactly as git wrote it
+func (g *Exec) RunRaw(args ...string) (string, error) {
+	return g.run(nil, args)
+}
+
+// RunInteractive executes git attached to the terminal, for commands that prompt the user
+func (g *Exec) RunInteractive(args ...string) error {
+	cmd := g.command(args)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	g.trace(args, start, err)
+	if err != nil {
+		return &Error{Args: args, Err: err}
+	}
+	return nil
+}
+
+// WithEnv returns a copy of g whose invocations also get the given environment variables
+func (g *Exec) WithEnv(env ...string) Git {
+	clone := *g
+	clone.Env = append(append([]string{}, g.Env...), env...)
+	return &clone
+}
+
+func (g *Exec) command(args []string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = g.Dir
+	if len(g.Env) > 0 {
+		cmd.Env = append(os.Environ(), g.Env...)
+	}
+	return cmd
+}
+
+func (g *Exec) run(stdin io.Reader, args []string) (string, error) {
+	cmd := g.command(args)
+	cmd.Stdin = stdin
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	g.trace(args, start, err)
+	if err != nil {
+		output := strings.TrimSpace(stdout.String() + "\n" + stderr.String())
+		return "", &Error{Args: args, Output: output, Err: err}
+	}
+	return stdout.String(), nil
+}
+
+func (g *Exec) trace(args []string, start time.Time, err error) {
+	if g.Trace == nil {
+		return
+	}
+	status := "ok"
+	if err != nil {
+		status = err.Error()
+	}
+	fmt.Fprintf(g.Trace, "[git] %s (%s, %s)\n", strings.Join(args, " "), time.Since(start).Round(time.Millisecond), status)
+}
@@ -0,0 +1,143 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestExecRunsInDirAndTraces(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	var trace bytes.Buffer
+	g := &Exec{Dir: dir, Trace: &trace}
+
+	if _, err := g.Run("init", "-q", "-b", "main"); err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+
+	output, err := g.Run("rev-parse", "--show-toplevel")
+	if err != nil {
+		t.Fatalf("Failed to run rev-parse: %v", err)
+	}
+	if !strings.HasSuffix(output, strings.TrimPrefix(dir, "/private")) {
+		t.Errorf("Expected git to run in %s, got toplevel %s", dir, output)
+	}
+
+	if !strings.Contains(trace.String(), "[git] rev-parse --show-toplevel") {
+		t.Errorf("Expected invocation to be traced, got: %s", trace.String())
+	}
+}
+
+func TestExecReturnsGitError(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	g := &Exec{Dir: t.TempDir()}
+	_, err := g.Run("rev-parse", "--verify", "does-not-exist")
+	if err == nil {
+		t.Fatal("Expected an error outside of a git repository")
+	}
+
+	var gitErr *Error
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("Expected *Error, got %T", err)
+	}
+	if strings.Join(gitErr.Args, " ") != "rev-parse --verify does-not-exist" {
+		t.Errorf("Unexpected args in error: %v", gitErr.Args)
+	}
+	if gitErr.Output == "" {
+		t.Error("Expected error to include git's output")
+	}
+}
+
+func TestExecRunWithInput(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	g := &Exec{Dir: t.TempDir()}
+	if _, err := g.Run("init", "-q"); err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+
+	output, err := g.RunWithInput("hello\n", "hash-object", "--stdin")
+	if err != nil {
+		t.Fatalf("Failed to hash object: %v", err)
+	}
+	if output != "ce013625030ba8dba906f756967f9e9ca394464a" {
+		t.Errorf("Unexpected blob id: %s", output)
+	}
+}
+
+func TestExecRunRawKeepsWhitespace(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	g := &Exec{Dir: t.TempDir()}
+	output, err := g.RunRaw("rev-parse", "--sq-quote", "a ")
+	if err != nil {
+		t.Fatalf("Failed to run rev-parse: %v", err)
+	}
+	if output != " 'a '\n" {
+		t.Errorf("Expected output untouched, got %q", output)
+	}
+}
+
+func TestExecWithEnv(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	g := &Exec{Dir: t.TempDir()}
+	if _, err := g.Run("init", "-q"); err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+
+	output, err := g.WithEnv("GIT_AUTHOR_NAME=Ada", "GIT_AUTHOR_EMAIL=ada@example.com").Run("var", "GIT_AUTHOR_IDENT")
+	if err != nil {
+		t.Fatalf("Failed to read author ident: %v", err)
+	}
+	if !strings.HasPrefix(output, "Ada <ada@example.com>") {
+		t.Errorf("Expected the author from the environment, got %q", output)
+	}
+	if len(g.Env) != 0 {
+		t.Errorf("Expected WithEnv to leave the original untouched, got %v", g.Env)
+	}
+}
+
+func TestFakeRecordsCalls(t *testing.T) {
+	f := NewFake()
+	f.On("rev-parse --abbrev-ref HEAD", "feature")
+	f.Fail("rev-parse --verify missing")
+
+	output, err := f.Run("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || output != "feature" {
+		t.Errorf("Expected canned output 'feature', got %q (err: %v)", output, err)
+	}
+
+	if _, err := f.Run("rev-parse", "--verify", "missing"); err == nil {
+		t.Error("Expected canned failure")
+	}
+
+	if _, err := f.RunWithInput("data", "hash-object", "--stdin"); err != nil {
+		t.Errorf("Expected unknown invocation to succeed, got %v", err)
+	}
+
+	if len(f.Calls) != 3 {
+		t.Fatalf("Expected 3 recorded calls, got %d", len(f.Calls))
+	}
+	if !f.Called("hash-object --stdin") {
+		t.Error("Expected hash-object call to be recorded")
+	}
+	if f.Inputs[2] != "data" {
+		t.Errorf("Expected input 'data' to be recorded, got %q", f.Inputs[2])
+	}
+}
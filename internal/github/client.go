@@ -0,0 +1,227 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the GitHub REST API endpoint used unless overridden
+const DefaultBaseURL = "https://api.github.com"
+
+// Client talks to the GitHub REST API for a single repository
+type Client struct {
+	BaseURL    string
+	Token      string
+	Owner      string
+	Repo       string
+	HTTPClient *http.Client
+}
+
+// PullRequest is the subset of a GitHub pull request used by gt
+type PullRequest struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Title   string `json:"title"`
+	Draft   bool   `json:"draft"`
+	Base    Ref    `json:"base"`
+	Head    Ref    `json:"head"`
+}
+
+// Ref is a branch reference within a pull request
+type Ref struct {
+	Ref string `json:"ref"`
+}
+
+// Branch is the subset of a GitHub branch used by gt
+type Branch struct {
+	Name string `json:"name"`
+}
+
+// APIError is returned when GitHub responds with a non-success status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GitHub API error (%d): %s", e.StatusCode, e.Message)
+}
+
+// NewClient creates a client for owner/repo
+func NewClient(baseURL, token, owner, repo string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Token:      token,
+		Owner:      owner,
+		Repo:       repo,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewClientFromEnv creates a client for owner/repo using GITHUB_TOKEN (or GH_TOKEN)
+// for authentication. GT_GITHUB_API_URL overrides the API endpoint, e.g. to
+// point at GitHub Enterprise or a local fake server.
+func NewClientFromEnv(owner, repo string) (*Client, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no GitHub token found; set GITHUB_TOKEN or GH_TOKEN")
+	}
+
+	baseURL := os.Getenv("GT_GITHUB_API_URL")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return NewClient(baseURL, token, owner, repo), nil
+}
+
+// remoteURLPattern matches the owner and repository in https, ssh and scp-style remote URLs
+var remoteURLPattern = regexp.MustCompile(`^(?:https?://|ssh://)?(?:[^@/]+@)?[^:/]+(?::\d+)?[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// ParseRemoteURL extracts the owner and repository name from a git remote URL
+func ParseRemoteURL(remoteURL string) (string, string, error) {
+	matches := remoteURLPattern.FindStringSubmatch(strings.TrimSpace(remoteURL))
+	if matches == nil {
+		return "", "", fmt.Errorf("cannot determine GitHub repository from remote URL '%s'", remoteURL)
+	}
+	return matches[1], matches[2], nil
+}
+
+// FindPullRequest returns the open pull request for the given head branch, or nil if there is none
+func (c *Client) FindPullRequest(head string) (*PullRequest, error) {
+	query := url.Values{}
+	query.Set("head", c.Owner+":"+head)
+	query.Set("state", "open")
+
+	var pulls []PullRequest
+	if err := c.do(http.MethodGet, c.repoPath("/pulls")+"?"+query.Encode(), nil, &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+	return &pulls[0], nil
+}
+
+// GetPullRequest fetches a pull request by number
+func (c *Client) GetPullRequest(number int) (*PullRequest, error) {
+	var pull PullRequest
+	if err := c.do(http.MethodGet, c.repoPath(fmt.Sprintf("/pulls/%d", number)), nil, &pull); err != nil {
+		return nil, err
+	}
+	return &pull, nil
+}
+
+// CreatePullRequest opens a pull request merging head into base
+func (c *Client) CreatePullRequest(head, base, title, body string, draft bool) (*PullRequest, error) {
+	request := map[string]interface{}{
+		"head":  head,
+		"base":  base,
+		"title": title,
+		"body":  body,
+		"draft": draft,
+	}
+
+	var pull PullRequest
+	if err := c.do(http.MethodPost, c.repoPath("/pulls"), request, &pull); err != nil {
+		return nil, err
+	}
+	return &pull, nil
+}
+
+// UpdatePullRequestBase changes the branch a pull request merges into
+func (c *Client) UpdatePullRequestBase(number int, base string) (*PullRequest, error) {
+	request := map[string]interface{}{
+		"base": base,
+	}
+
+	var pull PullRequest
+	if err := c.do(http.MethodPatch, c.repoPath(fmt.Sprintf("/pulls/%d", number)), request, &pull); err != nil {
+		return nil, err
+	}
+	return &pull, nil
+}
+
+// RenameBranch renames a branch of the repository. GitHub retargets open pull
+// requests from and to the branch to its new name.
+func (c *Client) RenameBranch(branch, newName string) (*Branch, error) {
+	request := map[string]interface{}{
+		"new_name": newName,
+	}
+
+	var renamed Branch
+	if err := c.do(http.MethodPost, c.repoPath(fmt.Sprintf("/branches/%s/rename", url.PathEscape(branch))), request, &renamed); err != nil {
+		return nil, err
+	}
+	return &renamed, nil
+}
+
+func (c *Client) repoPath(path string) string {
+	return fmt.Sprintf("/repos/%s/%s%s", url.PathEscape(c.Owner), url.PathEscape(c.Repo), path)
+}
+
+// do sends a JSON request and decodes the JSON response into out
+func (c *Client) do(method, path string, in interface{}, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read GitHub API response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			message = apiErr.Message
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: message}
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse GitHub API response: %w", err)
+		}
+	}
+	return nil
+}
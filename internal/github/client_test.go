@@ -0,0 +1,127 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestPullRequestLifecycle(t *testing.T) {
+	server := NewFakeServer("owner", "repo", "token")
+	defer server.Close()
+	client := NewClient(server.URL, "token", "owner", "repo")
+
+	pull, err := client.FindPullRequest("feature")
+	if err != nil {
+		t.Fatalf("FindPullRequest returned error: %v", err)
+	}
+	if pull != nil {
+		t.Fatalf("Expected no pull request, got #%d", pull.Number)
+	}
+
+	created, err := client.CreatePullRequest("feature", "main", "Add feature", "", true)
+	if err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+	if created.Number != 1 || created.HTMLURL != "https://github.com/owner/repo/pull/1" {
+		t.Errorf("Unexpected pull request: %+v", created)
+	}
+	if stored, _ := server.PullRequest(1); !stored.Draft {
+		t.Error("Expected pull request to be created as a draft")
+	}
+
+	found, err := client.FindPullRequest("feature")
+	if err != nil || found == nil || found.Number != 1 {
+		t.Fatalf("Expected to find pull request #1, got %+v (err: %v)", found, err)
+	}
+
+	updated, err := client.UpdatePullRequestBase(1, "other")
+	if err != nil {
+		t.Fatalf("UpdatePullRequestBase returned error: %v", err)
+	}
+	if updated.Base.Ref != "other" {
+		t.Errorf("Expected base 'other', got '%s'", updated.Base.Ref)
+	}
+
+	fetched, err := client.GetPullRequest(1)
+	if err != nil || fetched.Base.Ref != "other" {
+		t.Errorf("Expected fetched base 'other', got %+v (err: %v)", fetched, err)
+	}
+}
+
+func TestRenameBranchRetargetsPullRequests(t *testing.T) {
+	server := NewFakeServer("owner", "repo", "token")
+	defer server.Close()
+	client := NewClient(server.URL, "token", "owner", "repo")
+	server.AddBranch("feature")
+
+	if _, err := client.CreatePullRequest("feature", "main", "Add feature", "", false); err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+	if _, err := client.CreatePullRequest("child", "feature", "Add child", "", false); err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+
+	renamed, err := client.RenameBranch("feature", "renamed")
+	if err != nil {
+		t.Fatalf("RenameBranch returned error: %v", err)
+	}
+	if renamed.Name != "renamed" || !server.HasBranch("renamed") || server.HasBranch("feature") {
+		t.Errorf("Expected 'feature' to be renamed, got %+v", renamed)
+	}
+	if pull, _ := server.PullRequest(1); pull.Head.Ref != "renamed" {
+		t.Errorf("Expected PR #1 head 'renamed', got '%s'", pull.Head.Ref)
+	}
+	if pull, _ := server.PullRequest(2); pull.Base.Ref != "renamed" {
+		t.Errorf("Expected PR #2 base 'renamed', got '%s'", pull.Base.Ref)
+	}
+
+	var apiErr *APIError
+	if _, err := client.RenameBranch("missing", "other"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a 404 for a missing branch, got %v", err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	server := NewFakeServer("owner", "repo", "token")
+	defer server.Close()
+	client := NewClient(server.URL, "wrong", "owner", "repo")
+
+	_, err := client.GetPullRequest(1)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "Bad credentials" {
+		t.Errorf("Unexpected API error: %+v", apiErr)
+	}
+}
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		owner string
+		repo  string
+	}{
+		{"git@github.com:th1nkful/cli-gt.git", "th1nkful", "cli-gt"},
+		{"https://github.com/th1nkful/cli-gt.git", "th1nkful", "cli-gt"},
+		{"https://github.com/th1nkful/cli-gt", "th1nkful", "cli-gt"},
+		{"ssh://git@github.com:22/th1nkful/cli-gt.git", "th1nkful", "cli-gt"},
+		{"https://user@github.example.com/team/project/", "team", "project"},
+	}
+
+	for _, tt := range tests {
+		owner, repo, err := ParseRemoteURL(tt.url)
+		if err != nil {
+			t.Errorf("ParseRemoteURL(%q) returned error: %v", tt.url, err)
+			continue
+		}
+		if owner != tt.owner || repo != tt.repo {
+			t.Errorf("ParseRemoteURL(%q) = %s/%s; want %s/%s", tt.url, owner, repo, tt.owner, tt.repo)
+		}
+	}
+
+	if _, _, err := ParseRemoteURL("/local/path/repo.git"); err == nil {
+		t.Error("Expected an error for a local path remote")
+	}
+}
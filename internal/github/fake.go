@@ -0,0 +1,205 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FakeServer is an in-memory stand-in for the pull request and branch rename
+// endpoints of the GitHub REST API, for tests. Point a Client at its URL.
+type FakeServer struct {
+	*httptest.Server
+	// Token is the bearer token requests must carry
+	Token string
+
+	mu       sync.Mutex
+	owner    string
+	repo     string
+	pulls    map[int]*PullRequest
+	next     int
+	branches map[string]bool
+}
+
+// NewFakeServer starts a fake API serving owner/repo that accepts the given token
+func NewFakeServer(owner, repo, token string) *FakeServer {
+	f := &FakeServer{
+		Token:    token,
+		owner:    owner,
+		repo:     repo,
+		pulls:    make(map[int]*PullRequest),
+		next:     1,
+		branches: make(map[string]bool),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	return f
+}
+
+// PullRequest returns a copy of the pull request with the given number, if it exists
+func (f *FakeServer) PullRequest(number int) (PullRequest, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	pull, ok := f.pulls[number]
+	if !ok {
+		return PullRequest{}, false
+	}
+	return *pull, true
+}
+
+// PullRequestCount returns the number of pull requests created so far
+func (f *FakeServer) PullRequestCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.pulls)
+}
+
+// AddBranch makes a branch exist on the fake, so that it can be renamed
+func (f *FakeServer) AddBranch(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.branches[name] = true
+}
+
+// HasBranch reports whether a branch exists on the fake
+func (f *FakeServer) HasBranch(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.branches[name]
+}
+
+func (f *FakeServer) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+f.Token {
+		writeFakeError(w, http.StatusUnauthorized, "Bad credentials")
+		return
+	}
+
+	branchPrefix := fmt.Sprintf("/repos/%s/%s/branches/", f.owner, f.repo)
+	if strings.HasPrefix(r.URL.Path, branchPrefix) {
+		f.serveBranch(w, r, strings.TrimPrefix(r.URL.Path, branchPrefix))
+		return
+	}
+
+	prefix := fmt.Sprintf("/repos/%s/%s/pulls", f.owner, f.repo)
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		writeFakeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, prefix)
+
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		head := strings.TrimPrefix(r.URL.Query().Get("head"), f.owner+":")
+		result := []PullRequest{}
+		for _, pull := range f.pulls {
+			if pull.Head.Ref == head && pull.State == "open" {
+				result = append(result, *pull)
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+	case path == "" && r.Method == http.MethodPost:
+		var request struct {
+			Head  string `json:"head"`
+			Base  string `json:"base"`
+			Title string `json:"title"`
+			Draft bool   `json:"draft"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeFakeError(w, http.StatusBadRequest, "Problems parsing JSON")
+			return
+		}
+		pull := &PullRequest{
+			Number:  f.next,
+			HTMLURL: fmt.Sprintf("https://github.com/%s/%s/pull/%d", f.owner, f.repo, f.next),
+			State:   "open",
+			Title:   request.Title,
+			Draft:   request.Draft,
+			Head:    Ref{Ref: request.Head},
+			Base:    Ref{Ref: request.Base},
+		}
+		f.pulls[pull.Number] = pull
+		f.next++
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(pull)
+	default:
+		number, err := strconv.Atoi(strings.TrimPrefix(path, "/"))
+		pull, ok := f.pulls[number]
+		if err != nil || !ok {
+			writeFakeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPatch:
+			var request struct {
+				Base string `json:"base"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				writeFakeError(w, http.StatusBadRequest, "Problems parsing JSON")
+				return
+			}
+			if request.Base != "" {
+				pull.Base.Ref = request.Base
+			}
+		default:
+			writeFakeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			return
+		}
+		json.NewEncoder(w).Encode(pull)
+	}
+}
+
+// serveBranch handles POST /branches/{branch}/rename, retargeting open pull
+// requests from and to the branch like GitHub does
+func (f *FakeServer) serveBranch(w http.ResponseWriter, r *http.Request, path string) {
+	branch, ok := strings.CutSuffix(path, "/rename")
+	if !ok || r.Method != http.MethodPost {
+		writeFakeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if !f.branches[branch] {
+		writeFakeError(w, http.StatusNotFound, "Branch not found")
+		return
+	}
+
+	var request struct {
+		NewName string `json:"new_name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.NewName == "" {
+		writeFakeError(w, http.StatusUnprocessableEntity, "Invalid request")
+		return
+	}
+	if f.branches[request.NewName] {
+		writeFakeError(w, http.StatusUnprocessableEntity, "New branch name already exists")
+		return
+	}
+
+	delete(f.branches, branch)
+	f.branches[request.NewName] = true
+	for _, pull := range f.pulls {
+		if pull.State != "open" {
+			continue
+		}
+		if pull.Head.Ref == branch {
+			pull.Head.Ref = request.NewName
+		}
+		if pull.Base.Ref == branch {
+			pull.Base.Ref = request.NewName
+		}
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Branch{Name: request.NewName})
+}
+
+func writeFakeError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}